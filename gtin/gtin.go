@@ -0,0 +1,93 @@
+// Package gtin provides parsing and validation of Global Trade Item Numbers
+// (GTIN), commonly known as EAN or UPC barcodes.
+//
+// https://www.gs1.org/standards/id-keys/gtin
+package gtin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GTIN is a Global Trade Item Number. It is stored as a string of digits
+// in the length it was parsed from: 8 (EAN-8), 12 (UPC-A), 13 (EAN-13) or 14 (GTIN-14).
+type GTIN string
+
+// Parse parses the given string as a GTIN-8, GTIN-12, GTIN-13 or GTIN-14,
+// and validates the check digit. Spaces and hyphens are ignored.
+func Parse(s string) (GTIN, error) {
+	n := make([]byte, 0, 14)
+	for _, c := range s {
+		switch c {
+		case ' ', '-':
+			continue
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if len(n) == 14 {
+				return "", fmt.Errorf("gtin: invalid: %q", s)
+			}
+			n = append(n, byte(c))
+		default:
+			return "", fmt.Errorf("gtin: unexpected character %q", string(c))
+		}
+	}
+
+	switch len(n) {
+	case 8, 12, 13, 14:
+	default:
+		// wrong number of digits
+		return "", fmt.Errorf("gtin: invalid: %q", s)
+	}
+
+	if CheckDigit(string(n[:len(n)-1])) != n[len(n)-1] {
+		return "", fmt.Errorf("gtin: invalid checksum for %q", s)
+	}
+
+	return GTIN(n), nil
+}
+
+// CheckDigit computes the modulo 10 check digit for the given digits, which
+// must not include the check digit itself. It is the same algorithm for
+// all GTIN lengths, as well as for ISBN13, ISMN and ISSN in EAN-13 form.
+func CheckDigit(digits string) byte {
+	s := 0
+	// Weights alternate 3,1,3.. from the rightmost digit.
+	for i := len(digits) - 1; i >= 0; i -= 2 {
+		s += 3 * int(digits[i]-'0')
+		if i > 0 {
+			s += int(digits[i-1] - '0')
+		}
+	}
+	return byte((10-s%10)%10) + '0'
+}
+
+// String returns the digits of the GTIN, in the length it was parsed from.
+func (g GTIN) String() string {
+	return string(g)
+}
+
+// GTIN14 returns the GTIN left-padded with zeros to 14 digits, which is the
+// canonical form for comparing and storing GTINs of different lengths.
+func (g GTIN) GTIN14() string {
+	return strings.Repeat("0", 14-len(g)) + string(g)
+}
+
+// EAN13 returns the GTIN as an EAN-13, and a boolean which is false if the GTIN
+// cannot be represented with 13 digits.
+func (g GTIN) EAN13() (string, bool) {
+	s := strings.TrimLeft(g.GTIN14(), "0")
+	if len(s) > 13 {
+		return "", false
+	}
+	return strings.Repeat("0", 13-len(s)) + s, true
+}
+
+// Prefix returns the GS1 prefix of the GTIN, the first three digits of its
+// EAN-13 form, ex: "978" for books (Bookland), or "977" for serials.
+// It returns an empty string if the GTIN has no EAN-13 form.
+func (g GTIN) Prefix() string {
+	ean, ok := g.EAN13()
+	if !ok {
+		return ""
+	}
+	return ean[:3]
+}
@@ -0,0 +1,57 @@
+package gtin
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in     string
+		gtin14 string
+		prefix string
+	}{
+		{"96385074", "00000096385074", "000"},
+		{"036000291452", "00036000291452", "003"},
+		{"978-82-530-0983-4", "09788253009834", "978"},
+		{"9771234567003", "09771234567003", "977"},
+		{"10012345678902", "10012345678902", ""},
+		{"00012345678905", "00012345678905", "001"},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got.GTIN14() != test.gtin14 {
+			t.Errorf("Parse(%q).GTIN14() got %q; want %q", test.in, got.GTIN14(), test.gtin14)
+		}
+		if got.Prefix() != test.prefix {
+			t.Errorf("Parse(%q).Prefix() got %q; want %q", test.in, got.Prefix(), test.prefix)
+		}
+	}
+}
+
+func TestParseBad(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"96385075", `gtin: invalid checksum for "96385075"`},
+		{"9788253009836", `gtin: invalid checksum for "9788253009836"`},
+		{"978825300983", `gtin: invalid checksum for "978825300983"`},
+		{"97882530098", `gtin: invalid: "97882530098"`},
+		{"100123456789021", `gtin: invalid: "100123456789021"`},
+		{"978825300983X", `gtin: unexpected character "X"`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.in)
+		if err == nil {
+			t.Errorf("Parse(%q) got nil; want error", test.in)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("Parse(%q) got %q; want %q", test.in, err.Error(), test.err)
+		}
+	}
+}
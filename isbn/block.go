@@ -0,0 +1,162 @@
+package isbn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Block is the set of ISBNs available to a registrant, ie. all the ISBNs
+// sharing the same prefix, registration group and registrant elements.
+type Block struct {
+	Prefix     string
+	Group      string
+	Registrant string
+	Agency     string
+}
+
+// NewBlock returns the Block of ISBNs for the given prefix, registration
+// group and registrant elements, using the Table currently in use. It returns
+// an error if the group is unknown, or the registrant is not in one of the
+// group's assigned ranges.
+func NewBlock(prefix, group, registrant string) (Block, error) {
+	return currentTable().NewBlock(prefix, group, registrant)
+}
+
+// NewBlock returns the Block of ISBNs for the given prefix, registration
+// group and registrant elements, using the ranges of the Table.
+func (t *Table) NewBlock(prefix, group, registrant string) (Block, error) {
+	g, ok := t.LookupGroup(prefix, group)
+	if !ok {
+		return Block{}, fmt.Errorf("isbn: unknown registration group %s-%s", prefix, group)
+	}
+	if _, ok := g.Registrant(registrant); !ok {
+		return Block{}, fmt.Errorf("%w: %s-%s-%s", ErrUnassigned, g.Prefix, g.Group, registrant)
+	}
+	return Block{
+		Prefix:     g.Prefix,
+		Group:      g.Group,
+		Registrant: registrant,
+		Agency:     g.Agency,
+	}, nil
+}
+
+// ParseBlock parses a publisher prefix, ex: "978-82-530" or "97882530", and
+// returns the corresponding Block, using the Table currently in use.
+// A prefix without the 978 or 979 element is treated as 978.
+func ParseBlock(s string) (Block, error) {
+	return currentTable().ParseBlock(s)
+}
+
+// ParseBlock parses a publisher prefix, ex: "978-82-530" or "97882530", and
+// returns the corresponding Block, using the ranges of the Table.
+func (t *Table) ParseBlock(s string) (Block, error) {
+	digits := Clean(s)
+	for _, c := range digits {
+		if !isDigit(c) {
+			return Block{}, fmt.Errorf("isbn: unexpected character %q", string(c))
+		}
+	}
+
+	prefix := "978"
+	if len(digits) > 3 && (digits[:3] == "978" || digits[:3] == "979") {
+		prefix = digits[:3]
+		digits = digits[3:]
+	}
+
+	for i := 5; i > 0; i-- {
+		if i >= len(digits) {
+			continue
+		}
+		if _, ok := t.groups[prefix+digits[:i]]; ok {
+			return t.NewBlock(prefix, digits[:i], digits[i:])
+		}
+	}
+
+	return Block{}, fmt.Errorf("isbn: invalid publisher prefix: %q", s)
+}
+
+// TitleLength returns the number of digits in the title element of ISBNs in the Block.
+func (b Block) TitleLength() int {
+	return 9 - len(b.Group) - len(b.Registrant)
+}
+
+// Size returns the number of ISBNs in the Block.
+func (b Block) Size() int {
+	n := 1
+	for i := 0; i < b.TitleLength(); i++ {
+		n *= 10
+	}
+	return n
+}
+
+// Contains reports whether the given title element is within the Block,
+// that is, it has the length required by the Block and consists only of digits.
+func (b Block) Contains(title string) bool {
+	if len(title) != b.TitleLength() {
+		return false
+	}
+	for _, c := range title {
+		if !isDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsISBN reports whether the given ISBN belongs to the Block.
+func (b Block) ContainsISBN(isbn ISBN) bool {
+	prefix := isbn.Prefix
+	if prefix == "" {
+		prefix = "978"
+	}
+	return prefix == b.Prefix && isbn.Group == b.Group && isbn.Publisher == b.Registrant && b.Contains(isbn.Title)
+}
+
+// ISBN returns the n-th ISBN of the Block, counting from 0. It returns an
+// error if n is outside the Block.
+func (b Block) ISBN(n int) (ISBN, error) {
+	if n < 0 || n >= b.Size() {
+		return ISBN{}, fmt.Errorf("isbn: title number %d outside of block %s-%s-%s of size %d",
+			n, b.Prefix, b.Group, b.Registrant, b.Size())
+	}
+	title := strconv.Itoa(n)
+	title = strings.Repeat("0", b.TitleLength()-len(title)) + title
+
+	return ISBN{
+		Prefix:     b.Prefix,
+		Group:      b.Group,
+		Publisher:  b.Registrant,
+		Title:      title,
+		CheckDigit: checkDigit13(b.Prefix + b.Group + b.Registrant + title),
+		Agency:     b.Agency,
+	}, nil
+}
+
+// Each calls fn for each ISBN of the Block in sequence, starting at the
+// n-th ISBN, until fn returns false or the Block is exhausted.
+func (b Block) Each(n int, fn func(ISBN) bool) {
+	for ; n < b.Size(); n++ {
+		isbn, err := b.ISBN(n)
+		if err != nil || !fn(isbn) {
+			return
+		}
+	}
+}
+
+func (b Block) String() string {
+	return fmt.Sprintf("%s-%s-%s", b.Prefix, b.Group, b.Registrant)
+}
+
+// checkDigit13 computes the ISBN13 check digit for the given 12 digits.
+func checkDigit13(digits string) string {
+	s := 0
+	for i, c := range digits {
+		if i%2 == 0 {
+			s += int(c - '0')
+		} else {
+			s += 3 * int(c-'0')
+		}
+	}
+	return strconv.Itoa((10 - s%10) % 10)
+}
@@ -0,0 +1,85 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBlock(t *testing.T) {
+	b, err := ParseBlock("978-82-530")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "978-82-530" || b.Agency != "Norway" {
+		t.Errorf("ParseBlock(%q) got %v (%s)", "978-82-530", b, b.Agency)
+	}
+	if b.Size() != 10000 {
+		t.Errorf("Block.Size() got %d; want %d", b.Size(), 10000)
+	}
+
+	isbn, err := b.ISBN(983)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := isbn.Hypenate(); got != "978-82-530-0983-4" {
+		t.Errorf("Block.ISBN(983) got %q; want %q", got, "978-82-530-0983-4")
+	}
+	if _, err := b.ISBN(b.Size()); err == nil {
+		t.Errorf("Block.ISBN(%d) got nil; want error", b.Size())
+	}
+
+	for _, title := range []string{"0000", "0983", "9999"} {
+		if !b.Contains(title) {
+			t.Errorf("Block.Contains(%q) got false; want true", title)
+		}
+	}
+	for _, title := range []string{"", "983", "00983", "09a3"} {
+		if b.Contains(title) {
+			t.Errorf("Block.Contains(%q) got true; want false", title)
+		}
+	}
+
+	for _, s := range []string{"8253009836", "9788253009834"} {
+		parsed, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !b.ContainsISBN(parsed) {
+			t.Errorf("Block.ContainsISBN(%q) got false; want true", s)
+		}
+	}
+}
+
+func TestBlockEach(t *testing.T) {
+	b, err := NewBlock("978", "82", "690000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != 10 {
+		t.Fatalf("Block.Size() got %d; want %d", b.Size(), 10)
+	}
+
+	n := 0
+	b.Each(0, func(isbn ISBN) bool {
+		n++
+		if _, err := Parse(isbn.Hypenate()); err != nil {
+			t.Errorf("generated ISBN %s is not valid: %v", isbn.Hypenate(), err)
+		}
+		return true
+	})
+	if n != b.Size() {
+		t.Errorf("Block.Each visited %d ISBNs; want %d", n, b.Size())
+	}
+}
+
+func TestBlockErrors(t *testing.T) {
+	if _, err := NewBlock("979", "12", "000"); !errors.Is(err, ErrUnassigned) {
+		t.Errorf("NewBlock(979, 12, 000) got %v; want %v", err, ErrUnassigned)
+	}
+	if _, err := NewBlock("979", "82", "530"); err == nil {
+		t.Error("NewBlock(979, 82, 530) got nil; want error")
+	}
+	if _, err := ParseBlock("978-82"); err == nil {
+		t.Errorf("ParseBlock(%q) got nil; want error", "978-82")
+	}
+}
@@ -0,0 +1,135 @@
+// Command isbn validates, hyphenates and converts ISBNs in bulk.
+//
+// It reads one identifier per line from stdin, or from a column of CSV input,
+// and outputs a tab-separated line (or CSV record) for each identifier, with the
+// following columns appended:
+//
+//	status   valid, unassigned or invalid
+//	isbn13   hyphenated ISBN13
+//	isbn10   hyphenated ISBN10, if any
+//	agency   the registration group agency, ex: "Norway"
+//	error    the reason the identifier is not valid
+//
+// Usage:
+//
+//	isbn [flags] < input
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/knakk/kbp/isbn"
+)
+
+var (
+	csvInput = flag.Bool("csv", false, "read input as CSV, and output CSV")
+	column   = flag.Int("col", 1, "column holding the identifier, when reading CSV (counting from 1)")
+	header   = flag.Bool("header", false, "the first CSV record is a header row")
+	ranges   = flag.String("ranges", "", "RangeMessage.xml to use instead of the ranges compiled into the program")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("isbn: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: isbn [flags] < input\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *ranges != "" {
+		if _, err := isbn.LoadFile(*ranges); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var err error
+	if *csvInput {
+		w := csv.NewWriter(os.Stdout)
+		err = processCSV(csv.NewReader(os.Stdin), w)
+		w.Flush()
+		if err == nil {
+			err = w.Error()
+		}
+	} else {
+		w := bufio.NewWriter(os.Stdout)
+		err = processLines(os.Stdin, w)
+		if ferr := w.Flush(); err == nil {
+			err = ferr
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// processLines writes a tab-separated line for each identifier read from r,
+// one per line.
+func processLines(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if sc.Text() == "" {
+			continue
+		}
+		line := append([]string{sc.Text()}, check(sc.Text())...)
+		if _, err := io.WriteString(w, strings.Join(line, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func processCSV(r *csv.Reader, w *csv.Writer) error {
+	r.FieldsPerRecord = -1
+	first := true
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first && *header {
+			first = false
+			if err := w.Write(append(rec, "status", "isbn13", "isbn10", "agency", "error")); err != nil {
+				return err
+			}
+			continue
+		}
+		first = false
+
+		var id string
+		if *column > 0 && *column <= len(rec) {
+			id = rec[*column-1]
+		}
+		if err := w.Write(append(rec, check(id)...)); err != nil {
+			return err
+		}
+	}
+}
+
+// check returns the status, isbn13, isbn10, agency and error columns for the given identifier.
+func check(s string) []string {
+	res, err := isbn.Parse(isbn.Clean(s))
+	switch {
+	case errors.Is(err, isbn.ErrUnassigned):
+		return []string{"unassigned", "", "", res.Agency, err.Error()}
+	case err != nil:
+		return []string{"invalid", "", "", "", err.Error()}
+	}
+
+	var isbn10 string
+	if i, ok := res.ISBN10(); ok {
+		isbn10 = i.Hypenate()
+	}
+	return []string{"valid", res.ISBN13().Hypenate(), isbn10, res.Agency, ""}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"978-0-306-40615-7", []string{"valid", "978-0-306-40615-7", "0-306-40615-2", "English language", ""}},
+		{"0306406152", []string{"valid", "978-0-306-40615-7", "0-306-40615-2", "English language", ""}},
+		{"9791200000006", []string{"unassigned", "", "", "Italy", `isbn: registrant not in an assigned range: "9791200000006"`}},
+		{"123", []string{"invalid", "", "", "", `isbn: invalid: "123"`}},
+	}
+	for _, test := range tests {
+		if got := check(test.input); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("check(%q) got %q; want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestProcessLines(t *testing.T) {
+	input := "978-0-306-40615-7\n\n123\n"
+	want := "978-0-306-40615-7\tvalid\t978-0-306-40615-7\t0-306-40615-2\tEnglish language\t\n" +
+		"123\tinvalid\t\t\t\tisbn: invalid: \"123\"\n"
+
+	var out bytes.Buffer
+	if err := processLines(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("processLines got:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
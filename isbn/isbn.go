@@ -1,6 +1,7 @@
 package isbn
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -14,8 +15,17 @@ type ISBN struct {
 	Publisher  string
 	Title      string
 	CheckDigit string
+
+	// Agency is the name of the registration group, which is
+	// either a country, a geographical region or a language area.
+	Agency string
 }
 
+// ErrUnassigned is returned by Parse when the ISBN belongs to a known
+// registration group, but the registrant element is not within any of
+// the ranges assigned to that group.
+var ErrUnassigned = errors.New("isbn: registrant not in an assigned range")
+
 func isDigit(c rune) bool {
 	return c >= '0' && c <= '9'
 }
@@ -48,7 +58,16 @@ func numToChar(c rune) rune {
 	return c + '0'
 }
 
+// Parse parses the given string as an ISBN10 or ISBN13, using the registration
+// groups and ranges of the table currently in use. See Load for how to replace
+// the table compiled into the package.
 func Parse(s string) (ISBN, error) {
+	return currentTable().Parse(s)
+}
+
+// Parse parses the given string as an ISBN10 or ISBN13, using the registration
+// groups and ranges of the Table.
+func (t *Table) Parse(s string) (ISBN, error) {
 	var res ISBN
 	var n [13]byte
 	i := 0
@@ -105,32 +124,44 @@ loop:
 		isbnstring = isbnstring[:10]
 	}
 
+	// ISBN10 are always in the 978 prefix
+	prefix := res.Prefix
+	if prefix == "" {
+		prefix = "978"
+	}
+
 	// Find registration group, length 1-5 digits
 	for i := 5; i > 0; i-- {
-		if group, ok := ranges[isbnstring[0:i]]; ok {
-			res.Group = isbnstring[0:i]
-			// Find registrant (publisher)
-			for j := len(group.Ranges) - 1; j >= 0; j-- {
-				l := len(group.Ranges[j][0])
-				if i+l > len(isbnstring) {
-					// should not be possible given isbnstring is length 10 or 13,
-					// and none of the ranges ware more than 6 digits, but check just
-					// in case to avoid any out of bounds panic
-					continue
-				}
-				cand := isbnstring[i : i+l]
-				if cand >= group.Ranges[j][0] && cand <= group.Ranges[j][1] {
-					res.Publisher = cand
-					res.Title = isbnstring[i+l : len(isbnstring)-1]
-					res.CheckDigit = isbnstring[len(isbnstring)-1:]
-					return res, nil
-				}
+		group, ok := t.groups[prefix+isbnstring[0:i]]
+		if !ok {
+			continue
+		}
+		res.Group = group.Group
+		res.Agency = group.Agency
+		// Find registrant (publisher)
+		for j := len(group.Ranges) - 1; j >= 0; j-- {
+			l := len(group.Ranges[j].Start)
+			if i+l > len(isbnstring) {
+				// should not be possible given isbnstring is length 10 or 13,
+				// and none of the ranges ware more than 6 digits, but check just
+				// in case to avoid any out of bounds panic
+				continue
+			}
+			cand := isbnstring[i : i+l]
+			if group.Ranges[j].Contains(cand) {
+				res.Publisher = cand
+				res.Title = isbnstring[i+l : len(isbnstring)-1]
+				res.CheckDigit = isbnstring[len(isbnstring)-1:]
+				return res, nil
 			}
 		}
+
+		// The group is known, but there is no range matching the registrant.
+		return res, fmt.Errorf("%w: %q", ErrUnassigned, s)
 	}
 
 	// Sucessfully parsed ISBN are returned by now, so if we got this far
-	// it means no matching isbn group and/or range.
+	// it means no matching isbn group.
 
 	return res, fmt.Errorf("isbn: invalid: %q", s)
 }
@@ -142,6 +173,42 @@ func (isbn ISBN) Hypenate() string {
 	return fmt.Sprintf("%s-%s-%s-%s", isbn.Group, isbn.Publisher, isbn.Title, isbn.CheckDigit)
 }
 
+// ISBN13 returns the ISBN converted to an ISBN13. If it is allready
+// an ISBN13, it is returned unmodified.
+func (isbn ISBN) ISBN13() ISBN {
+	if isbn.Prefix != "" {
+		return isbn
+	}
+	isbn.Prefix = "978"
+	isbn.CheckDigit = checkDigit13(isbn.Prefix + isbn.Group + isbn.Publisher + isbn.Title)
+	return isbn
+}
+
+// ISBN10 returns the ISBN converted to an ISBN10, and a boolean which is
+// false if there is no ISBN10 form, which is the case for ISBNs with the
+// 979 prefix.
+func (isbn ISBN) ISBN10() (ISBN, bool) {
+	switch isbn.Prefix {
+	case "":
+		return isbn, true
+	case "978":
+		isbn.Prefix = ""
+		isbn.CheckDigit = checkDigit10(isbn.Group + isbn.Publisher + isbn.Title)
+		return isbn, true
+	default:
+		return ISBN{}, false
+	}
+}
+
+// checkDigit10 computes the ISBN10 check digit for the given 9 digits.
+func checkDigit10(digits string) string {
+	s := 0
+	for i, c := range digits {
+		s += (10 - i) * int(c-'0')
+	}
+	return string(numToChar(rune((11 - s%11) % 11)))
+}
+
 // Prettify will attempt to parse the given string as an ISBN number, and output a
 // hypenated representation. If the input is not a valid ISBN, it will be return unmodified.
 func Prettify(s string) string {
@@ -1,6 +1,9 @@
 package isbn
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestPrettify(t *testing.T) {
 	tests := []struct {
@@ -136,3 +139,99 @@ func TestParseBad(t *testing.T) {
 	}
 
 }
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		in     string
+		prefix string
+		group  string
+		agency string
+	}{
+		{"8253009836", "", "82", "Norway"},
+		{"9788253009834", "978", "82", "Norway"},
+		{"0330284983", "", "0", "English language"},
+		{"9791032300824", "979", "10", "France"},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got.Prefix != test.prefix || got.Group != test.group || got.Agency != test.agency {
+			t.Errorf("Parse(%q) got %s-%s (%s); want %s-%s (%s)",
+				test.in, got.Prefix, got.Group, got.Agency, test.prefix, test.group, test.agency)
+		}
+	}
+}
+
+func TestParseUnassigned(t *testing.T) {
+	got, err := Parse("9791200000006")
+	if !errors.Is(err, ErrUnassigned) {
+		t.Fatalf("Parse(%q) got error %v; want %v", "9791200000006", err, ErrUnassigned)
+	}
+	if got.Group != "12" || got.Agency != "Italy" {
+		t.Errorf("Parse(%q) got group %q (%s); want %q (%s)", "9791200000006", got.Group, got.Agency, "12", "Italy")
+	}
+}
+
+func TestLookupGroup(t *testing.T) {
+	g, ok := LookupGroup("978", "82")
+	if !ok {
+		t.Fatal("LookupGroup(978, 82) not found")
+	}
+	if g.Agency != "Norway" || len(g.Ranges) == 0 {
+		t.Errorf("LookupGroup(978, 82) got %+v", g)
+	}
+	if r, ok := g.Registrant("530"); !ok || !r.Contains("530") {
+		t.Errorf("Group.Registrant(%q) got %v, %v; want range containing it", "530", r, ok)
+	}
+
+	if _, ok := LookupGroup("979", "82"); ok {
+		t.Error("LookupGroup(979, 82) found; want not found")
+	}
+
+	groups := Groups()
+	if len(groups) != len(ranges) {
+		t.Errorf("Groups() returned %d groups; want %d", len(groups), len(ranges))
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		isbn10 string
+		isbn13 string
+	}{
+		{"82-530-0983-6", "978-82-530-0983-4"},
+		{"966-95440-5-X", "978-966-95440-5-6"},
+		{"0-330-28498-3", "978-0-330-28498-1"},
+	}
+
+	for _, test := range tests {
+		isbn10, err := Parse(test.isbn10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isbn10.ISBN13().Hypenate(); got != test.isbn13 {
+			t.Errorf("%s: ISBN13() got %q; want %q", test.isbn10, got, test.isbn13)
+		}
+
+		isbn13, err := Parse(test.isbn13)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := isbn13.ISBN10()
+		if !ok || got.Hypenate() != test.isbn10 {
+			t.Errorf("%s: ISBN10() got %q, %v; want %q", test.isbn13, got.Hypenate(), ok, test.isbn10)
+		}
+	}
+
+	isbn, err := Parse("9791032300824")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := isbn.ISBN10(); ok {
+		t.Errorf("%s: ISBN10() got ok; want no ISBN10 form", isbn.Hypenate())
+	}
+}
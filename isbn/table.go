@@ -0,0 +1,304 @@
+package isbn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Group is an ISBN registration group, along with the registrant
+// ranges which have been assigned within it.
+type Group struct {
+	Prefix string // 978 or 979
+	Group  string
+	Agency string
+	Ranges []Range
+}
+
+// Range is an interval of registrant elements. Start and End
+// are of equal length, which is also the length of all
+// registrant elements within the Range.
+type Range struct {
+	Start string
+	End   string
+}
+
+// Contains reports whether the given registrant element is within the Range.
+func (r Range) Contains(registrant string) bool {
+	return len(registrant) == len(r.Start) && registrant >= r.Start && registrant <= r.End
+}
+
+// Registrant returns the Range containing the given registrant element, and a
+// boolean which is false if the registrant is not in any assigned range.
+func (g Group) Registrant(registrant string) (Range, bool) {
+	for _, r := range g.Ranges {
+		if r.Contains(registrant) {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+// Table holds the registration groups and registrant ranges used
+// to validate and hyphenate ISBNs.
+type Table struct {
+	// Serial and Date identifies the RangeMessage the Table was read from.
+	// They are empty for the Table compiled into the package.
+	Serial string
+	Date   string
+
+	groups map[string]Group // keyed by prefix+group, ex: "97882"
+}
+
+// embedded is the Table generated from ranges.go.
+var embedded = newEmbeddedTable()
+
+// current holds the *Table in use by the package level functions.
+var current atomic.Value
+
+func init() {
+	current.Store(embedded)
+}
+
+func newEmbeddedTable() *Table {
+	t := &Table{groups: make(map[string]Group, len(ranges))}
+	for group, r := range ranges {
+		g := Group{
+			Prefix: r.Prefix,
+			Group:  group,
+			Agency: r.Agency,
+			Ranges: make([]Range, len(r.Ranges)),
+		}
+		for i, rng := range r.Ranges {
+			g.Ranges[i] = Range{Start: rng[0], End: rng[1]}
+		}
+		t.groups[g.Prefix+g.Group] = g
+	}
+	return t
+}
+
+func currentTable() *Table {
+	return current.Load().(*Table)
+}
+
+// Embedded returns the Table compiled into the package.
+func Embedded() *Table {
+	return embedded
+}
+
+// Current returns the Table in use by Parse, LookupGroup and Groups.
+func Current() *Table {
+	return currentTable()
+}
+
+// LookupGroup returns the registration group identified by the given prefix
+// and group element, and a boolean which is false if no such group exists.
+// An empty prefix is treated as 978, as is the case for ISBN10.
+func (t *Table) LookupGroup(prefix, group string) (Group, bool) {
+	if prefix == "" {
+		prefix = "978"
+	}
+	g, ok := t.groups[prefix+group]
+	return g, ok
+}
+
+// Groups returns all registration groups in the Table, ordered by prefix and group element.
+func (t *Table) Groups() []Group {
+	res := make([]Group, 0, len(t.groups))
+	for _, g := range t.groups {
+		res = append(res, g)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Prefix != res[j].Prefix {
+			return res[i].Prefix < res[j].Prefix
+		}
+		return res[i].Group < res[j].Group
+	})
+	return res
+}
+
+// LookupGroup returns the registration group identified by the given prefix
+// and group element in the Table currently in use.
+func LookupGroup(prefix, group string) (Group, bool) {
+	return currentTable().LookupGroup(prefix, group)
+}
+
+// Groups returns all registration groups in the Table currently in use.
+func Groups() []Group {
+	return currentTable().Groups()
+}
+
+// rangeMessage is the structure of RangeMessage.xml, as published by
+// the International ISBN Agency at https://www.isbn-international.org/range_file_generation
+type rangeMessage struct {
+	XMLName             xml.Name `xml:"ISBNRangeMessage"`
+	MessageSource       string
+	MessageSerialNumber string
+	MessageDate         string
+	RegistrationGroups  []struct {
+		Prefix string // ex: "978-82"
+		Agency string
+		Rules  []struct {
+			Range  string // ex: "0000000-1999999"
+			Length int
+		} `xml:"Rules>Rule"`
+	} `xml:"RegistrationGroups>Group"`
+}
+
+// ReadRangeMessage reads a RangeMessage.xml and returns a Table with its
+// registration groups and ranges. Rules with a length of 0 denote
+// unassigned ranges and are left out.
+func ReadRangeMessage(r io.Reader) (*Table, error) {
+	var msg rangeMessage
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("isbn: invalid RangeMessage: %v", err)
+	}
+
+	t := &Table{
+		Serial: strings.TrimSpace(msg.MessageSerialNumber),
+		Date:   strings.TrimSpace(msg.MessageDate),
+		groups: make(map[string]Group, len(msg.RegistrationGroups)),
+	}
+	for _, rg := range msg.RegistrationGroups {
+		prefix := strings.Split(strings.TrimSpace(rg.Prefix), "-")
+		if len(prefix) != 2 {
+			return nil, fmt.Errorf("isbn: invalid RangeMessage: malformed group prefix %q", rg.Prefix)
+		}
+		g := Group{
+			Prefix: prefix[0],
+			Group:  prefix[1],
+			Agency: strings.TrimSpace(rg.Agency),
+		}
+		for _, rule := range rg.Rules {
+			if rule.Length == 0 {
+				continue
+			}
+			rng := strings.Split(strings.TrimSpace(rule.Range), "-")
+			if len(rng) != 2 || len(rng[0]) < rule.Length || len(rng[1]) < rule.Length {
+				return nil, fmt.Errorf("isbn: invalid RangeMessage: malformed range %q in group %s", rule.Range, rg.Prefix)
+			}
+			g.Ranges = append(g.Ranges, Range{
+				Start: rng[0][:rule.Length],
+				End:   rng[1][:rule.Length],
+			})
+		}
+		t.groups[g.Prefix+g.Group] = g
+	}
+
+	if len(t.groups) == 0 {
+		return nil, fmt.Errorf("isbn: invalid RangeMessage: no registration groups")
+	}
+
+	return t, nil
+}
+
+// Change describes how a registration group differs between two Tables.
+type Change struct {
+	Prefix string
+	Group  string
+	Agency string
+
+	// PrevAgency is the agency of the group in the previous Table, if it
+	// changed. It is empty for new groups.
+	PrevAgency string
+
+	Added   []Range
+	Removed []Range
+}
+
+func (c Change) String() string {
+	agency := c.Agency
+	if c.PrevAgency != "" {
+		agency = c.PrevAgency + " -> " + c.Agency
+	}
+	return fmt.Sprintf("%s-%s (%s): +%d -%d ranges", c.Prefix, c.Group, agency, len(c.Added), len(c.Removed))
+}
+
+// Diff returns the changes needed to go from the prev to the next Table,
+// ordered by prefix and group element. Groups without any changes, to
+// their ranges or agency, are not included.
+func Diff(prev, next *Table) []Change {
+	var res []Change
+	for key, ng := range next.groups {
+		c := Change{Prefix: ng.Prefix, Group: ng.Group, Agency: ng.Agency}
+		og, ok := prev.groups[key]
+		if ok && og.Agency != ng.Agency {
+			c.PrevAgency = og.Agency
+		}
+		c.Added = rangesNotIn(ng.Ranges, og.Ranges)
+		c.Removed = rangesNotIn(og.Ranges, ng.Ranges)
+		if len(c.Added) > 0 || len(c.Removed) > 0 || c.PrevAgency != "" {
+			res = append(res, c)
+		}
+	}
+	for key, og := range prev.groups {
+		if _, ok := next.groups[key]; ok {
+			continue
+		}
+		res = append(res, Change{
+			Prefix:  og.Prefix,
+			Group:   og.Group,
+			Agency:  og.Agency,
+			Removed: og.Ranges,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Prefix != res[j].Prefix {
+			return res[i].Prefix < res[j].Prefix
+		}
+		return res[i].Group < res[j].Group
+	})
+	return res
+}
+
+func rangesNotIn(a, b []Range) []Range {
+	var res []Range
+outer:
+	for _, r := range a {
+		for _, other := range b {
+			if r == other {
+				continue outer
+			}
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// Use replaces the Table used by the package level functions, returning
+// the changes from the previous Table. The swap is atomic, so it is safe
+// to call Use while other goroutines are parsing ISBNs.
+// Use(Embedded()), or Use(nil), restores the ranges compiled into the
+// package.
+func Use(t *Table) []Change {
+	if t == nil {
+		t = Embedded()
+	}
+	prev := current.Swap(t).(*Table)
+	return Diff(prev, t)
+}
+
+// Load reads a RangeMessage.xml from r and replaces the Table used
+// by the package level functions, returning the changed ranges.
+// If the RangeMessage cannot be read, the Table in use is kept.
+func Load(r io.Reader) ([]Change, error) {
+	t, err := ReadRangeMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	return Use(t), nil
+}
+
+// LoadFile is like Load, but reads the RangeMessage.xml from the named file.
+func LoadFile(filename string) ([]Change, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
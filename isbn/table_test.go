@@ -0,0 +1,130 @@
+package isbn
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRangeMessage(t *testing.T) {
+	f, err := os.Open("testdata/RangeMessage.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tbl, err := ReadRangeMessage(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tbl.Date != "Mon, 19 Oct 2026 09:12:44 BST" {
+		t.Errorf("Table.Date got %q", tbl.Date)
+	}
+
+	want, _ := Embedded().LookupGroup("978", "82")
+	if got, ok := tbl.LookupGroup("978", "82"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("LookupGroup(978, 82) got:\n%v\nwant:\n%v", got, want)
+	}
+
+	g, ok := tbl.LookupGroup("979", "12")
+	if !ok {
+		t.Fatal("LookupGroup(979, 12) not found")
+	}
+	want = Group{
+		Prefix: "979",
+		Group:  "12",
+		Agency: "Italy",
+		Ranges: []Range{{"000", "199"}, {"200", "299"}},
+	}
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("LookupGroup(979, 12) got:\n%v\nwant:\n%v", g, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	defer Use(Embedded())
+
+	if _, err := Parse("9791200000006"); err == nil {
+		t.Fatalf("Parse(%q) with embedded table succeeded; want error", "9791200000006")
+	}
+
+	changes, err := LoadFile("testdata/RangeMessage.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var italy *Change
+	for i, c := range changes {
+		if c.Prefix == "979" && c.Group == "12" {
+			italy = &changes[i]
+		}
+		if c.Prefix == "978" && c.Group == "82" {
+			t.Errorf("got change for unchanged group: %v", c)
+		}
+	}
+	if italy == nil {
+		t.Fatal("no change reported for 979-12")
+	}
+	wantAdded := []Range{{"000", "199"}}
+	wantRemoved := []Range{{"5450", "5999"}, {"80000", "84999"}}
+	if !reflect.DeepEqual(italy.Added, wantAdded) || !reflect.DeepEqual(italy.Removed, wantRemoved) {
+		t.Errorf("change for 979-12 got +%v -%v; want +%v -%v", italy.Added, italy.Removed, wantAdded, wantRemoved)
+	}
+
+	if got := Prettify("9791200000006"); got != "979-12-000-0000-6" {
+		t.Errorf("Prettify(%q) got %q; want %q", "9791200000006", got, "979-12-000-0000-6")
+	}
+
+	Use(Embedded())
+	if _, err := Parse("9791200000006"); err == nil {
+		t.Errorf("Parse(%q) after restoring embedded table succeeded; want error", "9791200000006")
+	}
+}
+
+func TestReadRangeMessageErrors(t *testing.T) {
+	if _, err := Load(strings.NewReader("<ISBNRangeMessage></ISBNRangeMessage>")); err == nil {
+		t.Error("Load(no groups) got nil; want error")
+	}
+	if Current() != Embedded() {
+		t.Error("failed Load replaced the table in use")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	prev := &Table{groups: map[string]Group{
+		"97882": {Prefix: "978", Group: "82", Agency: "Norway", Ranges: []Range{{"00", "19"}}},
+		"97887": {Prefix: "978", Group: "87", Agency: "Denmark", Ranges: []Range{{"00", "29"}}},
+		"97891": {Prefix: "978", Group: "91", Agency: "Sweden", Ranges: []Range{{"0", "1"}}},
+	}}
+	next := &Table{groups: map[string]Group{
+		"97882": {Prefix: "978", Group: "82", Agency: "Norge", Ranges: []Range{{"00", "19"}}},
+		"97887": {Prefix: "978", Group: "87", Agency: "Denmark", Ranges: []Range{{"00", "29"}}},
+		"97891": {Prefix: "978", Group: "91", Agency: "Sweden", Ranges: []Range{{"0", "1"}, {"20", "49"}}},
+	}}
+
+	want := []Change{
+		{Prefix: "978", Group: "82", Agency: "Norge", PrevAgency: "Norway"},
+		{Prefix: "978", Group: "91", Agency: "Sweden", Added: []Range{{"20", "49"}}},
+	}
+	if got := Diff(prev, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff got:\n%v\nwant:\n%v", got, want)
+	}
+	if got := want[0].String(); got != "978-82 (Norway -> Norge): +0 -0 ranges" {
+		t.Errorf("Change.String() got %q", got)
+	}
+}
+
+func TestUseNil(t *testing.T) {
+	defer Use(Embedded())
+	if _, err := LoadFile("testdata/RangeMessage.xml"); err != nil {
+		t.Fatal(err)
+	}
+	if changes := Use(nil); len(changes) == 0 {
+		t.Error("Use(nil) got no changes; want changes back to the embedded table")
+	}
+	if Current() != Embedded() {
+		t.Error("Use(nil) did not restore the embedded table")
+	}
+}
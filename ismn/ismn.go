@@ -0,0 +1,107 @@
+// Package ismn provides parsing and validation of International Standard
+// Music Numbers (ISMN), in both the current 13-digit form and the legacy
+// 10-character form beginning with "M".
+//
+// https://www.ismn-international.org/whatis.html
+package ismn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knakk/kbp/gtin"
+)
+
+// ISMN is an International Standard Music Number.
+type ISMN struct {
+	Publisher  string
+	Item       string
+	CheckDigit string
+}
+
+// publisherRanges are the publisher element ranges defined by the
+// International ISMN Agency. All ranges have the same start and end
+// lengths, which is the length of the publisher element.
+var publisherRanges = [][2]string{
+	{"000", "099"},
+	{"1000", "3999"},
+	{"40000", "69999"},
+	{"700000", "899999"},
+	{"9000000", "9999999"},
+}
+
+// Parse parses the given string as an ISMN, and validates the check digit.
+// Both the 13-digit form (979-0-060-11561-5) and the legacy form
+// (M-060-11561-5) are accepted, optionally prefixed by the label "ISMN".
+// Spaces and hyphens are ignored.
+func Parse(s string) (ISMN, error) {
+	v := strings.TrimSpace(s)
+	if len(v) >= 4 && strings.EqualFold(v[:4], "ISMN") {
+		v = strings.TrimLeft(v[4:], ": ")
+	}
+
+	n := make([]byte, 0, 13)
+	for i, c := range v {
+		switch c {
+		case ' ', '-':
+			continue
+		case 'm', 'M':
+			if i != 0 {
+				return ISMN{}, fmt.Errorf("ismn: unexpected character %q", string(c))
+			}
+			// The legacy M is equivalent to the prefix 979-0
+			n = append(n, "9790"...)
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if len(n) == 13 {
+				return ISMN{}, fmt.Errorf("ismn: invalid: %q", s)
+			}
+			n = append(n, byte(c))
+		default:
+			return ISMN{}, fmt.Errorf("ismn: unexpected character %q", string(c))
+		}
+	}
+
+	if len(n) != 13 {
+		// wrong number of digits
+		return ISMN{}, fmt.Errorf("ismn: invalid: %q", s)
+	}
+
+	if gtin.CheckDigit(string(n[:12])) != n[12] {
+		return ISMN{}, fmt.Errorf("ismn: invalid checksum for %q", s)
+	}
+
+	if string(n[:4]) != "9790" {
+		return ISMN{}, fmt.Errorf("ismn: unknown prefix %q", string(n[:4]))
+	}
+
+	digits := string(n[4:12])
+	for _, r := range publisherRanges {
+		cand := digits[:len(r[0])]
+		if cand >= r[0] && cand <= r[1] {
+			return ISMN{
+				Publisher:  cand,
+				Item:       digits[len(cand):],
+				CheckDigit: string(n[12:]),
+			}, nil
+		}
+	}
+
+	// The publisher ranges cover all possible digits, so this should not happen.
+	return ISMN{}, fmt.Errorf("ismn: invalid: %q", s)
+}
+
+// String returns the ISMN in its canonical hyphenated 13-digit form, ex: "979-0-060-11561-5".
+func (ismn ISMN) String() string {
+	return fmt.Sprintf("979-0-%s-%s-%s", ismn.Publisher, ismn.Item, ismn.CheckDigit)
+}
+
+// Legacy returns the ISMN in the hyphenated 10-character form used before
+// 2008, ex: "M-060-11561-5".
+func (ismn ISMN) Legacy() string {
+	return fmt.Sprintf("M-%s-%s-%s", ismn.Publisher, ismn.Item, ismn.CheckDigit)
+}
+
+// EAN13 returns the ISMN as 13 digits without hyphens.
+func (ismn ISMN) EAN13() string {
+	return "9790" + ismn.Publisher + ismn.Item + ismn.CheckDigit
+}
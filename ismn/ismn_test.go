@@ -0,0 +1,59 @@
+package ismn
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"9790060115615", "979-0-060-11561-5"},
+		{"979-0-060-11561-5", "979-0-060-11561-5"},
+		{"M-060-11561-5", "979-0-060-11561-5"},
+		{"M060115615", "979-0-060-11561-5"},
+		{"ISMN 979-0-3452-4680-5", "979-0-3452-4680-5"},
+		{"9790501234561", "979-0-50123-456-1"},
+		{"9790800000003", "979-0-800000-00-3"},
+		{"9790900000002", "979-0-9000000-0-2"},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Parse(%q) got %q; want %q", test.in, got.String(), test.want)
+		}
+	}
+
+	i, _ := Parse("9790060115615")
+	if got := i.Legacy(); got != "M-060-11561-5" {
+		t.Errorf("Legacy() got %q; want %q", got, "M-060-11561-5")
+	}
+}
+
+func TestParseBad(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"9790060115616", `ismn: invalid checksum for "9790060115616"`},
+		{"M-060-11561-6", `ismn: invalid checksum for "M-060-11561-6"`},
+		{"979006011561", `ismn: invalid: "979006011561"`},
+		{"9788253009834", `ismn: unknown prefix "9788"`},
+		{"060-M11561-5", `ismn: unexpected character "M"`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.in)
+		if err == nil {
+			t.Errorf("Parse(%q) got nil; want error", test.in)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("Parse(%q) got %q; want %q", test.in, err.Error(), test.err)
+		}
+	}
+}
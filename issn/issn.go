@@ -0,0 +1,142 @@
+// Package issn provides parsing and validation of International Standard
+// Serial Numbers (ISSN), including linking ISSNs (ISSN-L) and ISSNs
+// embedded in EAN-13 barcodes.
+//
+// https://www.issn.org/understanding-the-issn/what-is-an-issn/
+package issn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knakk/kbp/gtin"
+)
+
+// ISSN is an International Standard Serial Number.
+type ISSN struct {
+	Number     string // the first 7 digits
+	CheckDigit string // 0-9 or X
+
+	// Linking is true if the ISSN was labelled as an ISSN-L, which is the
+	// ISSN designated to link together the different media versions of
+	// a continuing resource.
+	Linking bool
+}
+
+func checkDigit(digits string) string {
+	s := 0
+	for i, c := range digits {
+		s += (8 - i) * int(c-'0')
+	}
+	switch c := (11 - s%11) % 11; c {
+	case 10:
+		return "X"
+	default:
+		return string(rune('0' + c))
+	}
+}
+
+// Parse parses the given string as an ISSN, and validates the check digit.
+// The string may be prefixed by the label "ISSN" or "ISSN-L", in which case
+// the Linking field is set. Spaces and hyphens are ignored.
+func Parse(s string) (ISSN, error) {
+	var res ISSN
+
+	v := strings.TrimSpace(s)
+	switch {
+	case hasLabel(v, "ISSN-L"):
+		res.Linking = true
+		v = v[6:]
+	case hasLabel(v, "ISSN"):
+		v = v[4:]
+	}
+	v = strings.TrimLeft(v, ": ")
+
+	n := make([]byte, 0, 8)
+	for _, c := range v {
+		switch c {
+		case ' ', '-':
+			continue
+		case 'x', 'X':
+			if len(n) != 7 {
+				return ISSN{}, fmt.Errorf("issn: unexpected character %q", string(c))
+			}
+			n = append(n, 'X')
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if len(n) == 8 {
+				return ISSN{}, fmt.Errorf("issn: invalid: %q", s)
+			}
+			n = append(n, byte(c))
+		default:
+			return ISSN{}, fmt.Errorf("issn: unexpected character %q", string(c))
+		}
+	}
+
+	if len(n) != 8 {
+		// wrong number of digits
+		return ISSN{}, fmt.Errorf("issn: invalid: %q", s)
+	}
+
+	res.Number = string(n[:7])
+	res.CheckDigit = string(n[7:])
+	if checkDigit(res.Number) != res.CheckDigit {
+		return ISSN{}, fmt.Errorf("issn: invalid checksum for %q", s)
+	}
+
+	return res, nil
+}
+
+func hasLabel(s, label string) bool {
+	return len(s) >= len(label) && strings.EqualFold(s[:len(label)], label)
+}
+
+// FromEAN13 extracts the ISSN from an EAN-13 with the 977 prefix, as printed
+// in barcodes on serials. The EAN-13 consists of the prefix, the first 7
+// digits of the ISSN, a 2-digit variant code and the EAN check digit.
+func FromEAN13(s string) (ISSN, error) {
+	g, err := gtin.Parse(s)
+	if err != nil {
+		return ISSN{}, fmt.Errorf("issn: invalid EAN-13 %q: %v", s, err)
+	}
+	ean, ok := g.EAN13()
+	if !ok || g.Prefix() != "977" {
+		return ISSN{}, fmt.Errorf("issn: not an ISSN EAN-13: %q", s)
+	}
+
+	return ISSN{
+		Number:     ean[3:10],
+		CheckDigit: checkDigit(ean[3:10]),
+	}, nil
+}
+
+// EAN13 returns the ISSN as an EAN-13 with the 977 prefix and the given
+// 2-digit variant code, which is usually "00". It returns "" for the zero
+// ISSN, or any ISSN without a 7-digit Number.
+func (issn ISSN) EAN13(variant string) string {
+	if len(issn.Number) != 7 {
+		return ""
+	}
+	s := "977" + issn.Number + variant
+	return s + string(gtin.CheckDigit(s))
+}
+
+// String returns the ISSN in its canonical hyphenated form, ex: "0317-8471".
+// It returns "" for the zero ISSN, or any ISSN without a 7-digit Number.
+func (issn ISSN) String() string {
+	if len(issn.Number) != 7 {
+		return ""
+	}
+	return issn.Number[:4] + "-" + issn.Number[4:] + issn.CheckDigit
+}
+
+// Label returns the ISSN prefixed with the label "ISSN" or "ISSN-L",
+// ex: "ISSN-L 0317-8471". It returns "" for the zero ISSN, like String.
+func (issn ISSN) Label() string {
+	if issn.String() == "" {
+		return ""
+	}
+	if issn.Linking {
+		return "ISSN-L " + issn.String()
+	}
+	return "ISSN " + issn.String()
+}
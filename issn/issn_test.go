@@ -0,0 +1,98 @@
+package issn
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0317-8471", "ISSN 0317-8471"},
+		{"03178471", "ISSN 0317-8471"},
+		{"ISSN 1050-124X", "ISSN 1050-124X"},
+		{"issn 1050-124x", "ISSN 1050-124X"},
+		{"ISSN-L 0378-5955", "ISSN-L 0378-5955"},
+		{"ISSN-L: 0378-5955", "ISSN-L 0378-5955"},
+		{"0029-1951", "ISSN 0029-1951"},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got.Label() != test.want {
+			t.Errorf("Parse(%q) got %q; want %q", test.in, got.Label(), test.want)
+		}
+	}
+}
+
+func TestParseBad(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"0317-8472", `issn: invalid checksum for "0317-8472"`},
+		{"1050-1241", `issn: invalid checksum for "1050-1241"`},
+		{"0317-847", `issn: invalid: "0317-847"`},
+		{"0317-84711", `issn: invalid: "0317-84711"`},
+		{"031X-8471", `issn: unexpected character "X"`},
+		{"ISBN 0317-8471", `issn: unexpected character "I"`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.in)
+		if err == nil {
+			t.Errorf("Parse(%q) got nil; want error", test.in)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("Parse(%q) got %q; want %q", test.in, err.Error(), test.err)
+		}
+	}
+}
+
+func TestZero(t *testing.T) {
+	var issn ISSN
+	if s := issn.String(); s != "" {
+		t.Errorf("ISSN{}.String() => %q; want \"\"", s)
+	}
+	if s := issn.Label(); s != "" {
+		t.Errorf("ISSN{}.Label() => %q; want \"\"", s)
+	}
+	if s := issn.EAN13("00"); s != "" {
+		t.Errorf("ISSN{}.EAN13(\"00\") => %q; want \"\"", s)
+	}
+}
+
+func TestEAN13(t *testing.T) {
+	tests := []struct {
+		ean  string
+		issn string
+	}{
+		{"9770317847001", "0317-8471"},
+		{"9771050124008", "1050-124X"},
+		{"977-0029-195-00-1", "0029-1951"},
+	}
+
+	for _, test := range tests {
+		got, err := FromEAN13(test.ean)
+		if err != nil {
+			t.Errorf("FromEAN13(%q) failed: %v", test.ean, err)
+			continue
+		}
+		if got.String() != test.issn {
+			t.Errorf("FromEAN13(%q) got %q; want %q", test.ean, got.String(), test.issn)
+		}
+	}
+
+	i, _ := Parse("0317-8471")
+	if got := i.EAN13("00"); got != "9770317847001" {
+		t.Errorf("EAN13(%q) got %q; want %q", "00", got, "9770317847001")
+	}
+
+	if _, err := FromEAN13("9788253009834"); err == nil {
+		t.Errorf("FromEAN13(%q) got nil; want error", "9788253009834")
+	}
+}
@@ -0,0 +1,232 @@
+package ncip
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// Client is an NCIP client, which sends requests to an NCIP responder over
+// HTTP. It is safe for concurrent use.
+type Client struct {
+	url string
+
+	// FromAgency and ToAgency are the agency IDs of the initiator and the
+	// responder, set in the InitiationHeader of requests which have none.
+	FromAgency string
+	ToAgency   string
+
+	// Timeout is the maximum duration of a request-response roundtrip.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// If Validation is true, responses are checked with Validate, and Do
+	// returns the problems found, if any, along with the response.
+	Validation bool
+
+	// HTTPClient is the client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a new Client for the NCIP responder at the given URL,
+// sending requests from the agency fromAgency to the agency toAgency.
+func NewClient(url, fromAgency, toAgency string) *Client {
+	return &Client{
+		url:        url,
+		FromAgency: fromAgency,
+		ToAgency:   toAgency,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// Do sends the request and returns the response. The request is not
+// modified; if it has no InitiationHeader, or one without agency IDs, the
+// Client's agency IDs are sent.
+//
+// If the responder reports Problems, Do returns a *ProblemError, along with
+// the response with the Problems, if any. Other errors are from the
+// transport, or a response which cannot be decoded or is of the wrong type.
+func (c *Client) Do(req Request) (Response, error) {
+	if rv := reflect.ValueOf(req); req == nil || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, fmt.Errorf("ncip: cannot send nil request")
+	}
+	var body bytes.Buffer
+	if err := EncodeRequest(&body, c.withHeader(req)); err != nil {
+		return nil, err
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &body)
+	if err != nil {
+		return nil, fmt.Errorf("ncip: %w", err)
+	}
+	hreq.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	hresp, err := client.Do(hreq)
+	if err != nil {
+		return nil, fmt.Errorf("ncip: %w", err)
+	}
+	defer hresp.Body.Close()
+	if hresp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ncip: %s", hresp.Status)
+	}
+
+	resp, problems, err := decodeResponse(hresp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ncip: %w", err)
+	}
+	if resp == nil {
+		return nil, &ProblemError{Problems: problems}
+	}
+	if want := requestNames[req.Type()] + "Response"; responseNames[resp.Type()] != want {
+		return nil, fmt.Errorf("ncip: got %s; want %s", responseNames[resp.Type()], want)
+	}
+	if p := reflect.ValueOf(resp).Elem().FieldByName("Problem").Interface().([]Problem); len(p) > 0 {
+		return resp, &ProblemError{Problems: p, Response: resp}
+	}
+	if c.Validation {
+		if err := Validate(resp); err != nil {
+			err.(*ProblemError).Response = resp
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// withHeader returns a copy of the request with the agency IDs of the
+// Client in the InitiationHeader, unless it has them already.
+func (c *Client) withHeader(req Request) Request {
+	rv := reflect.ValueOf(req)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	cp := reflect.New(rv.Type())
+	cp.Elem().Set(rv)
+
+	hv := cp.Elem().FieldByName("InitiationHeader")
+	h := &InitiationHeader{}
+	if old := hv.Interface().(*InitiationHeader); old != nil {
+		*h = *old
+	}
+	if h.FromAgencyId.AgencyId.Value == "" {
+		h.FromAgencyId.AgencyId = SchemeValue{Value: c.FromAgency}
+	}
+	if h.ToAgencyId.AgencyId.Value == "" {
+		h.ToAgencyId.AgencyId = SchemeValue{Value: c.ToAgency}
+	}
+	hv.Set(reflect.ValueOf(h))
+	return cp.Interface().(Request)
+}
+
+// decodeResponse decodes a response, or the Problems reported directly in
+// the NCIPMessage element, if the responder could not identify the request.
+func decodeResponse(r io.Reader) (Response, []Problem, error) {
+	dec := xml.NewDecoder(r)
+	elem, err := messageElement(dec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if elem.Name.Local != "Problem" {
+		newResp, ok := responseTypes[elem.Name.Local]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown response: %s", elem.Name.Local)
+		}
+		resp := newResp()
+		err := dec.DecodeElement(resp, &elem)
+		return resp, nil, err
+	}
+
+	var problems []Problem
+	for {
+		var p Problem
+		if err := dec.DecodeElement(&p, &elem); err != nil {
+			return nil, nil, err
+		}
+		problems = append(problems, p)
+		if elem, err = nextElement(dec); err == io.EOF {
+			return nil, problems, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// nextElement returns the next start element, or io.EOF if there are no
+// more elements in the enclosing element.
+func nextElement(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			return t, nil
+		case xml.EndElement:
+			return xml.StartElement{}, io.EOF
+		}
+	}
+}
+
+// LookupUser sends a LookupUser request.
+func (c *Client) LookupUser(req *LookupUser) (*LookupUserResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*LookupUserResponse)
+	return r, err
+}
+
+// LookupItem sends a LookupItem request.
+func (c *Client) LookupItem(req *LookupItem) (*LookupItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*LookupItemResponse)
+	return r, err
+}
+
+// CheckOutItem sends a CheckOutItem request.
+func (c *Client) CheckOutItem(req *CheckOutItem) (*CheckOutItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*CheckOutItemResponse)
+	return r, err
+}
+
+// CheckInItem sends a CheckInItem request.
+func (c *Client) CheckInItem(req *CheckInItem) (*CheckInItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*CheckInItemResponse)
+	return r, err
+}
+
+// RenewItem sends a RenewItem request.
+func (c *Client) RenewItem(req *RenewItem) (*RenewItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*RenewItemResponse)
+	return r, err
+}
+
+// RequestItem sends a RequestItem request.
+func (c *Client) RequestItem(req *RequestItem) (*RequestItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*RequestItemResponse)
+	return r, err
+}
+
+// CancelRequestItem sends a CancelRequestItem request.
+func (c *Client) CancelRequestItem(req *CancelRequestItem) (*CancelRequestItemResponse, error) {
+	resp, err := c.Do(req)
+	r, _ := resp.(*CancelRequestItemResponse)
+	return r, err
+}
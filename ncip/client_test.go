@@ -0,0 +1,131 @@
+package ncip
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	s := NewServer(nil)
+	s.ErrorLog = log.New(io.Discard, "", 0)
+	var header *InitiationHeader
+	s.HandleFunc(TypeLookupUser, func(req Request) (Response, error) {
+		header = req.(*LookupUser).InitiationHeader
+		return &LookupUserResponse{UserId: req.(*LookupUser).UserId}, nil
+	})
+	s.HandleFunc(TypeRenewItem, func(req Request) (Response, error) {
+		return &RenewItemResponse{
+			ItemId: &req.(*RenewItem).ItemId,
+			Problem: []Problem{{
+				ProblemType:    SchemeValue{Value: "Maximum Renewals Exceeded"},
+				ProblemElement: "ItemIdentifierValue",
+				ProblemValue:   "15",
+			}},
+		}, nil
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	c := NewClient(srv.URL, "API9999", "LEHI")
+
+	req := &LookupUser{UserId: &UserId{UserIdentifierValue: "p1"}}
+	resp, err := c.LookupUser(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserId == nil || resp.UserId.UserIdentifierValue != "p1" {
+		t.Errorf("LookupUser => %+v; want response with user p1", resp)
+	}
+	want := &InitiationHeader{
+		FromAgencyId: FromAgencyId{AgencyId: SchemeValue{Value: "API9999"}},
+		ToAgencyId:   ToAgencyId{AgencyId: SchemeValue{Value: "LEHI"}},
+	}
+	if !reflect.DeepEqual(header, want) {
+		t.Errorf("LookupUser sent header %+v; want %+v", header, want)
+	}
+	if req.InitiationHeader != nil {
+		t.Error("LookupUser modified the request")
+	}
+
+	// Agency IDs in the request are kept.
+	req.InitiationHeader = &InitiationHeader{ToAgencyId: ToAgencyId{AgencyId: SchemeValue{Value: "OTHER"}}}
+	if _, err := c.LookupUser(req); err != nil {
+		t.Fatal(err)
+	}
+	if header.ToAgencyId.AgencyId.Value != "OTHER" || header.FromAgencyId.AgencyId.Value != "API9999" {
+		t.Errorf("LookupUser sent header %+v; want from API9999 to OTHER", header)
+	}
+
+	renew, err := c.RenewItem(&RenewItem{ItemId: ItemId{ItemIdentifierValue: "15"}})
+	var pe *ProblemError
+	if !errors.As(err, &pe) {
+		t.Fatalf("RenewItem => %v; want *ProblemError", err)
+	}
+	if !errors.Is(err, ErrMaximumRenewalsExceeded) || errors.Is(err, ErrItemNotRenewable) {
+		t.Errorf("RenewItem => %v; want error matching ErrMaximumRenewalsExceeded only", err)
+	}
+	if want := "ncip: Maximum Renewals Exceeded (ItemIdentifierValue 15)"; pe.Error() != want {
+		t.Errorf("RenewItem error => %q; want %q", pe.Error(), want)
+	}
+	if renew == nil || pe.Response != renew || renew.ItemId.ItemIdentifierValue != "15" {
+		t.Errorf("RenewItem => %+v; want the response with the problem", renew)
+	}
+
+	// The server reports unsupported services in a response of the
+	// corresponding type.
+	if _, err := c.CheckInItem(&CheckInItem{}); !errors.Is(err, ErrUnsupportedService) {
+		t.Errorf("CheckInItem => %v; want Unsupported Service problem", err)
+	}
+
+	if _, err := c.Do(nil); err == nil {
+		t.Error("Do(nil) => nil error; want error")
+	}
+	if _, err := c.LookupItem(nil); err == nil {
+		t.Error("LookupItem(nil) => nil error; want error")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			io.WriteString(w, `<NCIPMessage><Problem><ProblemType>Unknown Agency</ProblemType></Problem><Problem><ProblemType>Unsupported Service</ProblemType></Problem></NCIPMessage>`)
+		case "/wrong":
+			io.WriteString(w, `<NCIPMessage><CheckInItemResponse/></NCIPMessage>`)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/problem", "a", "b")
+	_, err := c.LookupUser(&LookupUser{})
+	var pe *ProblemError
+	if !errors.As(err, &pe) || len(pe.Problems) != 2 || pe.Response != nil {
+		t.Errorf("LookupUser => %v; want *ProblemError with 2 problems", err)
+	}
+
+	c = NewClient(srv.URL+"/wrong", "a", "b")
+	if _, err := c.LookupUser(&LookupUser{}); err == nil || errors.As(err, &pe) {
+		t.Errorf("LookupUser with wrong response type => %v; want error", err)
+	}
+
+	c = NewClient(srv.URL+"/slow", "a", "b")
+	c.Timeout = 50 * time.Millisecond
+	if _, err := c.LookupUser(&LookupUser{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupUser with timeout => %v; want context.DeadlineExceeded", err)
+	}
+
+	c = NewClient(srv.URL+"/missing", "a", "b")
+	if _, err := c.LookupUser(&LookupUser{}); err == nil {
+		t.Error("LookupUser from missing endpoint => nil error; want error")
+	}
+}
@@ -6,11 +6,6 @@ import (
 	"io"
 )
 
-const (
-	header = `<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE NCIPMessage xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="http://www.niso.org/ncip/v2_02/imp1/xsd/ncip_v2_02.xsd" version="http://www.niso.org/ncip/v2_02/imp1/xsd/ncip_v2_02.xsd">`
-)
-
 type NCIPRequestMessage struct {
 	XMLName xml.Name `xml:"NCIPMessage"`
 	Request
@@ -21,80 +16,150 @@ type NCIPResponseMessage struct {
 	Response
 }
 
+// DecodeRequest decodes an NCIP request message. The message element may be
+// left out, in which case the first element must be the request.
 func DecodeRequest(r io.Reader) (Request, error) {
 	dec := xml.NewDecoder(r)
-
-	for {
-		t, _ := dec.Token()
-		if t == nil {
-			break
-		}
-		switch elem := t.(type) {
-		case xml.StartElement:
-			var reqItem Request
-			switch elem.Name.Local {
-			case "NCIPMessage":
-				continue
-			case "RequestItem":
-				reqItem = &RequestItem{}
-			case "LookupUser":
-				reqItem = &LookupUser{}
-			case "CheckOutItem":
-				reqItem = &CheckOutItem{}
-			case "CheckInItem":
-				reqItem = &CheckInItem{}
-			case "AcceptItem":
-				reqItem = &AcceptItem{}
-			case "RenewItem":
-				reqItem = &RenewItem{}
-			case "CancelRequestItem":
-				reqItem = &CancelRequestItem{}
-			default:
-				return reqItem, fmt.Errorf("TODO: Decode %s", elem.Name.Local)
-			}
-			err := dec.DecodeElement(reqItem, &elem)
-			return reqItem, err
-		}
+	elem, err := messageElement(dec)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, io.EOF
+	newReq, ok := requestTypes[elem.Name.Local]
+	if !ok {
+		return nil, fmt.Errorf("ncip: unknown request: %s", elem.Name.Local)
+	}
+	req := newReq()
+	err = dec.DecodeElement(req, &elem)
+	return req, err
 }
 
+// DecodeResponse decodes an NCIP response message. The message element may be
+// left out, in which case the first element must be the response.
 func DecodeResponse(r io.Reader) (Response, error) {
 	dec := xml.NewDecoder(r)
+	elem, err := messageElement(dec)
+	if err != nil {
+		return nil, err
+	}
+	newResp, ok := responseTypes[elem.Name.Local]
+	if !ok {
+		return nil, fmt.Errorf("ncip: unknown response: %s", elem.Name.Local)
+	}
+	resp := newResp()
+	err = dec.DecodeElement(resp, &elem)
+	return resp, err
+}
 
+// messageElement returns the first element in the NCIPMessage element, or
+// the first element, if it is not an NCIPMessage element.
+func messageElement(dec *xml.Decoder) (xml.StartElement, error) {
 	for {
-		t, _ := dec.Token()
-		if t == nil {
-			break
+		t, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
 		}
-		switch elem := t.(type) {
-		case xml.StartElement:
-			var respItem Response
-			switch elem.Name.Local {
-			case "NCIPMessage":
-				continue
-			case "LookupUserResponse":
-				respItem = &LookupUserResponse{}
-			case "CheckOutItemResponse":
-				respItem = &CheckOutItemResponse{}
-			case "CheckInItemResponse":
-				respItem = &CheckInItemResponse{}
-			case "AcceptItemResponse":
-				respItem = &AcceptItemResponse{}
-			case "RenewItemResponse":
-				respItem = &RenewItemResponse{}
-			case "RequestItemResponse":
-				respItem = &RequestItemResponse{}
-			case "CancelRequestItemResponse":
-				respItem = &CancelRequestItemResponse{}
-			default:
-				return respItem, fmt.Errorf("TODO: Decode %s", elem.Name.Local)
-			}
-			err := dec.DecodeElement(respItem, &elem)
-			return respItem, err
+		if elem, ok := t.(xml.StartElement); ok && elem.Name.Local != "NCIPMessage" {
+			return elem, nil
 		}
 	}
+}
+
+// requestTypes are the constructors of the requests, by element name.
+var requestTypes = map[string]func() Request{
+	"AcceptItem":                      func() Request { return &AcceptItem{} },
+	"AgencyCreated":                   func() Request { return &AgencyCreated{} },
+	"AgencyUpdated":                   func() Request { return &AgencyUpdated{} },
+	"CancelRecallItem":                func() Request { return &CancelRecallItem{} },
+	"CancelRequestItem":               func() Request { return &CancelRequestItem{} },
+	"CheckInItem":                     func() Request { return &CheckInItem{} },
+	"CheckOutItem":                    func() Request { return &CheckOutItem{} },
+	"CirculationStatusChangeReported": func() Request { return &CirculationStatusChangeReported{} },
+	"CirculationStatusUpdated":        func() Request { return &CirculationStatusUpdated{} },
+	"CreateAgency":                    func() Request { return &CreateAgency{} },
+	"CreateItem":                      func() Request { return &CreateItem{} },
+	"CreateUser":                      func() Request { return &CreateUser{} },
+	"CreateUserFiscalTransaction":     func() Request { return &CreateUserFiscalTransaction{} },
+	"DeleteItem":                      func() Request { return &DeleteItem{} },
+	"DeleteUser":                      func() Request { return &DeleteUser{} },
+	"ItemCheckedIn":                   func() Request { return &ItemCheckedIn{} },
+	"ItemCheckedOut":                  func() Request { return &ItemCheckedOut{} },
+	"ItemCreated":                     func() Request { return &ItemCreated{} },
+	"ItemRecallCancelled":             func() Request { return &ItemRecallCancelled{} },
+	"ItemRecalled":                    func() Request { return &ItemRecalled{} },
+	"ItemReceived":                    func() Request { return &ItemReceived{} },
+	"ItemRenewed":                     func() Request { return &ItemRenewed{} },
+	"ItemRequestCancelled":            func() Request { return &ItemRequestCancelled{} },
+	"ItemRequestUpdated":              func() Request { return &ItemRequestUpdated{} },
+	"ItemRequested":                   func() Request { return &ItemRequested{} },
+	"ItemShipped":                     func() Request { return &ItemShipped{} },
+	"ItemUpdated":                     func() Request { return &ItemUpdated{} },
+	"LookupAgency":                    func() Request { return &LookupAgency{} },
+	"LookupItem":                      func() Request { return &LookupItem{} },
+	"LookupRequest":                   func() Request { return &LookupRequest{} },
+	"LookupUser":                      func() Request { return &LookupUser{} },
+	"RecallItem":                      func() Request { return &RecallItem{} },
+	"RenewItem":                       func() Request { return &RenewItem{} },
+	"ReportCirculationStatusChange":   func() Request { return &ReportCirculationStatusChange{} },
+	"RequestItem":                     func() Request { return &RequestItem{} },
+	"SendUserNotice":                  func() Request { return &SendUserNotice{} },
+	"UndoCheckOutItem":                func() Request { return &UndoCheckOutItem{} },
+	"UpdateAgency":                    func() Request { return &UpdateAgency{} },
+	"UpdateCirculationStatus":         func() Request { return &UpdateCirculationStatus{} },
+	"UpdateItem":                      func() Request { return &UpdateItem{} },
+	"UpdateRequestItem":               func() Request { return &UpdateRequestItem{} },
+	"UpdateUser":                      func() Request { return &UpdateUser{} },
+	"UserCreated":                     func() Request { return &UserCreated{} },
+	"UserFiscalTransactionCreated":    func() Request { return &UserFiscalTransactionCreated{} },
+	"UserNoticeSent":                  func() Request { return &UserNoticeSent{} },
+	"UserUpdated":                     func() Request { return &UserUpdated{} },
+}
 
-	return nil, io.EOF
+// responseTypes are the constructors of the responses, by element name.
+var responseTypes = map[string]func() Response{
+	"AcceptItemResponse":                      func() Response { return &AcceptItemResponse{} },
+	"AgencyCreatedResponse":                   func() Response { return &AgencyCreatedResponse{} },
+	"AgencyUpdatedResponse":                   func() Response { return &AgencyUpdatedResponse{} },
+	"CancelRecallItemResponse":                func() Response { return &CancelRecallItemResponse{} },
+	"CancelRequestItemResponse":               func() Response { return &CancelRequestItemResponse{} },
+	"CheckInItemResponse":                     func() Response { return &CheckInItemResponse{} },
+	"CheckOutItemResponse":                    func() Response { return &CheckOutItemResponse{} },
+	"CirculationStatusChangeReportedResponse": func() Response { return &CirculationStatusChangeReportedResponse{} },
+	"CirculationStatusUpdatedResponse":        func() Response { return &CirculationStatusUpdatedResponse{} },
+	"CreateAgencyResponse":                    func() Response { return &CreateAgencyResponse{} },
+	"CreateItemResponse":                      func() Response { return &CreateItemResponse{} },
+	"CreateUserResponse":                      func() Response { return &CreateUserResponse{} },
+	"CreateUserFiscalTransactionResponse":     func() Response { return &CreateUserFiscalTransactionResponse{} },
+	"DeleteItemResponse":                      func() Response { return &DeleteItemResponse{} },
+	"DeleteUserResponse":                      func() Response { return &DeleteUserResponse{} },
+	"ItemCheckedInResponse":                   func() Response { return &ItemCheckedInResponse{} },
+	"ItemCheckedOutResponse":                  func() Response { return &ItemCheckedOutResponse{} },
+	"ItemCreatedResponse":                     func() Response { return &ItemCreatedResponse{} },
+	"ItemRecallCancelledResponse":             func() Response { return &ItemRecallCancelledResponse{} },
+	"ItemRecalledResponse":                    func() Response { return &ItemRecalledResponse{} },
+	"ItemReceivedResponse":                    func() Response { return &ItemReceivedResponse{} },
+	"ItemRenewedResponse":                     func() Response { return &ItemRenewedResponse{} },
+	"ItemRequestCancelledResponse":            func() Response { return &ItemRequestCancelledResponse{} },
+	"ItemRequestUpdatedResponse":              func() Response { return &ItemRequestUpdatedResponse{} },
+	"ItemRequestedResponse":                   func() Response { return &ItemRequestedResponse{} },
+	"ItemShippedResponse":                     func() Response { return &ItemShippedResponse{} },
+	"ItemUpdatedResponse":                     func() Response { return &ItemUpdatedResponse{} },
+	"LookupAgencyResponse":                    func() Response { return &LookupAgencyResponse{} },
+	"LookupItemResponse":                      func() Response { return &LookupItemResponse{} },
+	"LookupRequestResponse":                   func() Response { return &LookupRequestResponse{} },
+	"LookupUserResponse":                      func() Response { return &LookupUserResponse{} },
+	"RecallItemResponse":                      func() Response { return &RecallItemResponse{} },
+	"RenewItemResponse":                       func() Response { return &RenewItemResponse{} },
+	"ReportCirculationStatusChangeResponse":   func() Response { return &ReportCirculationStatusChangeResponse{} },
+	"RequestItemResponse":                     func() Response { return &RequestItemResponse{} },
+	"SendUserNoticeResponse":                  func() Response { return &SendUserNoticeResponse{} },
+	"UndoCheckOutItemResponse":                func() Response { return &UndoCheckOutItemResponse{} },
+	"UpdateAgencyResponse":                    func() Response { return &UpdateAgencyResponse{} },
+	"UpdateCirculationStatusResponse":         func() Response { return &UpdateCirculationStatusResponse{} },
+	"UpdateItemResponse":                      func() Response { return &UpdateItemResponse{} },
+	"UpdateRequestItemResponse":               func() Response { return &UpdateRequestItemResponse{} },
+	"UpdateUserResponse":                      func() Response { return &UpdateUserResponse{} },
+	"UserCreatedResponse":                     func() Response { return &UserCreatedResponse{} },
+	"UserFiscalTransactionCreatedResponse":    func() Response { return &UserFiscalTransactionCreatedResponse{} },
+	"UserNoticeSentResponse":                  func() Response { return &UserNoticeSentResponse{} },
+	"UserUpdatedResponse":                     func() Response { return &UserUpdatedResponse{} },
 }
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -91,3 +93,62 @@ func TestResponseDecodeEncodeRoundtrip(t *testing.T) {
 	}
 
 }
+
+func TestDecodeAllTypes(t *testing.T) {
+	requests := make(map[requestType]bool)
+	for name, newReq := range requestTypes {
+		typ := newReq().Type()
+		if typ == illegalRequest || requests[typ] {
+			t.Errorf("%s.Type() => %d; want a unique request type", name, typ)
+		}
+		requests[typ] = true
+	}
+	if len(requests) != int(TypeUserUpdated) {
+		t.Errorf("got %d request types; want %d", len(requests), TypeUserUpdated)
+	}
+
+	responses := make(map[responseType]bool)
+	for name, newResp := range responseTypes {
+		typ := newResp().Type()
+		if typ == illegalResponse || responses[typ] {
+			t.Errorf("%s.Type() => %d; want a unique response type", name, typ)
+		}
+		responses[typ] = true
+	}
+	if len(responses) != int(TypeUserUpdatedResponse) {
+		t.Errorf("got %d response types; want %d", len(responses), TypeUserUpdatedResponse)
+	}
+
+	for _, files := range []struct {
+		name   string
+		decode func(io.Reader) (any, error)
+		types  int
+	}{
+		{"testdata/requests.xml", func(r io.Reader) (any, error) { return DecodeRequest(r) }, len(requests)},
+		{"testdata/responses.xml", func(r io.Reader) (any, error) { return DecodeResponse(r) }, len(responses)},
+	} {
+		b, err := os.ReadFile(files.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[string]bool)
+		r := bufio.NewReader(bytes.NewReader(b))
+		for msg := readMsg(r); msg.Len() > 0; msg = readMsg(r) {
+			v, err := files.decode(&msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen[fmt.Sprintf("%T", v)] = true
+		}
+		if len(seen) != files.types {
+			t.Errorf("%s has samples of %d types; want %d", files.name, len(seen), files.types)
+		}
+	}
+
+	if _, err := DecodeRequest(strings.NewReader("<NCIPMessage><FlyAway/></NCIPMessage>")); err == nil {
+		t.Error("DecodeRequest of unknown request => nil error; want error")
+	}
+	if _, err := DecodeResponse(strings.NewReader("<NCIPMessage><LookupUser/></NCIPMessage>")); err == nil {
+		t.Error("DecodeResponse of request => nil error; want error")
+	}
+}
@@ -0,0 +1,82 @@
+package ncip
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+const (
+	// Namespace is the XML namespace of NCIP version 2 messages.
+	Namespace = "http://www.niso.org/2008/ncip"
+
+	// Version identifies the version of the protocol in the version
+	// attribute of the NCIPMessage element.
+	Version = "http://www.niso.org/schemas/ncip/v2_02/ncip_v2_02.xsd"
+)
+
+// requestNames and responseNames are the element names of the messages,
+// by type.
+var (
+	requestNames  = make(map[requestType]string)
+	responseNames = make(map[responseType]string)
+)
+
+func init() {
+	for name, newReq := range requestTypes {
+		requestNames[newReq().Type()] = name
+	}
+	for name, newResp := range responseTypes {
+		responseNames[newResp().Type()] = name
+	}
+}
+
+// EncodeRequest writes the request as an NCIP 2.02 message, with an XML
+// declaration, to w. The element of the request is named after its type, so
+// its XMLName field need not be set.
+func EncodeRequest(w io.Writer, req Request) error {
+	if req == nil {
+		return errors.New("ncip: cannot encode nil request")
+	}
+	name, ok := requestNames[req.Type()]
+	if !ok {
+		return errors.New("ncip: cannot encode request of unknown type")
+	}
+	return encode(w, name, req)
+}
+
+// EncodeResponse writes the response as an NCIP 2.02 message, with an XML
+// declaration, to w. The element of the response is named after its type,
+// so its XMLName field need not be set.
+func EncodeResponse(w io.Writer, resp Response) error {
+	if resp == nil {
+		return errors.New("ncip: cannot encode nil response")
+	}
+	name, ok := responseNames[resp.Type()]
+	if !ok {
+		return errors.New("ncip: cannot encode response of unknown type")
+	}
+	return encode(w, name, resp)
+}
+
+// envelope is the start of the NCIPMessage element, in the NCIP namespace,
+// with the version attribute.
+const envelope = `<NCIPMessage xmlns="` + Namespace + `" xmlns:ncip="` + Namespace +
+	`" ncip:version="` + Version + `">` + "\n"
+
+// encode writes the message, enveloped in an NCIPMessage element.
+func encode(w io.Writer, name string, msg interface{}) error {
+	if _, err := io.WriteString(w, xml.Header+envelope); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("  ", "  ")
+	if err := enc.EncodeElement(msg, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n</NCIPMessage>\n")
+	return err
+}
@@ -0,0 +1,121 @@
+package ncip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeRoundtrip(t *testing.T) {
+	for _, file := range []string{"testdata/requests.xml", "testdata/responses.xml"} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r := bufio.NewReader(f)
+
+		for {
+			b := readMsg(r)
+			if b.Len() == 0 {
+				break
+			}
+			want := xml.Header + strings.Replace(b.String(), "<NCIPMessage>\n", envelope, 1)
+
+			var got bytes.Buffer
+			if file == "testdata/requests.xml" {
+				req, err := DecodeRequest(&b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := EncodeRequest(&got, req); err != nil {
+					t.Fatal(err)
+				}
+				if req, err = DecodeRequest(bytes.NewReader(got.Bytes())); err != nil {
+					t.Fatal(err)
+				}
+				got.Reset()
+				if err := EncodeRequest(&got, req); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				resp, err := DecodeResponse(&b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := EncodeResponse(&got, resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp, err = DecodeResponse(bytes.NewReader(got.Bytes())); err != nil {
+					t.Fatal(err)
+				}
+				got.Reset()
+				if err := EncodeResponse(&got, resp); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got.String() != want {
+				t.Errorf("\n\ngot:\n%s\nwant:\n%s", got.String(), want)
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	// The element is named after the type, even when XMLName is not set.
+	var b bytes.Buffer
+	err := EncodeRequest(&b, &LookupUser{
+		InitiationHeader: &InitiationHeader{
+			FromAgencyId: FromAgencyId{AgencyId: SchemeValue{Value: "API9999"}},
+			ToAgencyId:   ToAgencyId{AgencyId: SchemeValue{Value: "LEHI"}},
+		},
+		UserId: &UserId{UserIdentifierValue: "6010570002006861"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<NCIPMessage xmlns="http://www.niso.org/2008/ncip" xmlns:ncip="http://www.niso.org/2008/ncip" ncip:version="http://www.niso.org/schemas/ncip/v2_02/ncip_v2_02.xsd">
+  <LookupUser>
+    <InitiationHeader>
+      <FromAgencyId>
+        <AgencyId>API9999</AgencyId>
+      </FromAgencyId>
+      <ToAgencyId>
+        <AgencyId>LEHI</AgencyId>
+      </ToAgencyId>
+    </InitiationHeader>
+    <UserId>
+      <UserIdentifierValue>6010570002006861</UserIdentifierValue>
+    </UserId>
+  </LookupUser>
+</NCIPMessage>
+`
+	if b.String() != want {
+		t.Errorf("EncodeRequest =>\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := EncodeResponse(&b, RenewItemResponse{
+		ResponseHeader: &ResponseHeader{
+			FromAgencyId: FromAgencyId{AgencyId: SchemeValue{Value: "LEHI"}},
+			ToAgencyId:   ToAgencyId{AgencyId: SchemeValue{Value: "API9999"}},
+		},
+		Problem: []Problem{{ProblemType: SchemeValue{Value: "Maximum Renewals Exceeded"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "\n  <RenewItemResponse>\n    <ResponseHeader>\n") {
+		t.Errorf("EncodeResponse => \n%s\nwant RenewItemResponse element starting with ResponseHeader", b.String())
+	}
+
+	if err := EncodeRequest(&b, nil); err == nil {
+		t.Error("EncodeRequest(nil) => nil error; want error")
+	}
+	if err := EncodeResponse(&b, nil); err == nil {
+		t.Error("EncodeResponse(nil) => nil error; want error")
+	}
+}
@@ -84,6 +84,7 @@ type AgencyCreatedResponse struct {
 // message must include that element in both the delete and add sections of the
 // message.
 type AgencyUpdated struct {
+	XMLName            xml.Name
 	InitiationHeader   *InitiationHeader
 	AgencyId           SchemeValue
 	DeleteAgencyFields *DeleteAgencyFields
@@ -95,6 +96,7 @@ type AgencyUpdated struct {
 // The responding application replies that it understood the notification
 // message.
 type AgencyUpdatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -105,6 +107,7 @@ type AgencyUpdatedResponse struct {
 // initiating application may also request data about the User and/or Item
 // involved with this recall cancellation.
 type CancelRecallItem struct {
+	XMLName          xml.Name
 	InitiationHeader *InitiationHeader
 	MandatedAction   *MandatedAction
 	ItemId           ItemId
@@ -117,6 +120,7 @@ type CancelRecallItem struct {
 // The responding application cancels the recall of the Item and takes
 // appropriate fiscal actions. It may also supply the data elements requested.
 type CancelRecallItemResponse struct {
+	XMLName                      xml.Name
 	ResponseHeader               *ResponseHeader
 	Problem                      []Problem
 	ItemId                       ItemId
@@ -245,6 +249,7 @@ type CheckOutItemResponse struct {
 // borrowed. The initiating application may also request data about the User
 // and/or Item involved with this status change.
 type CirculationStatusChangeReported struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	ItemId                       ItemId
 	UserId                       UserId
@@ -263,6 +268,7 @@ type CirculationStatusChangeReported struct {
 // as specified by the initiator. It may also supply the data elements
 // requested.
 type CirculationStatusChangeReportedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -275,6 +281,7 @@ type CirculationStatusChangeReportedResponse struct {
 // are valid here: Renew Still Pending, Item Not Renewed, or Item Overdue. No
 // other values of Circulation Status are permitted for this service.
 type CirculationStatusUpdated struct {
+	XMLName           xml.Name
 	InitiationHeader  *InitiationHeader
 	ItemId            ItemId
 	CirculationStatus SchemeValue
@@ -285,6 +292,7 @@ type CirculationStatusUpdated struct {
 // CirculationStatusUpdate request. The responding application replies that it
 // understood the notification message.
 type CirculationStatusUpdatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -295,6 +303,7 @@ type CirculationStatusUpdatedResponse struct {
 // to be used to create the Agency. It may optionally supply a proposed Agency
 // Id.
 type CreateAgency struct {
+	XMLName                         xml.Name
 	InitiationHeader                *InitiationHeader
 	MandatedAction                  *MandatedAction
 	AgencyId                        *SchemeValue
@@ -311,6 +320,7 @@ type CreateAgency struct {
 // The responding application creates a record for an agency and returns a
 // Agency Id.
 type CreateAgencyResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	AgencyId       *SchemeValue
@@ -321,6 +331,7 @@ type CreateAgencyResponse struct {
 // record for an item. The initiating application supplies the data elements to
 // be used to create the Item. It may optionally supply a proposed Item Id.
 type CreateItem struct {
+	XMLName                  xml.Name
 	InitiationHeader         *InitiationHeader
 	MandatedAction           *MandatedAction
 	ItemId                   *ItemId
@@ -339,6 +350,7 @@ type CreateItem struct {
 // CreateItemResponse represents a response to a CreateItem request. The
 // responding application creates a record for an item and returns a Item Id.
 type CreateItemResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	ItemId         *ItemId
@@ -349,6 +361,7 @@ type CreateItemResponse struct {
 // record for a user. The initiating application supplies the data elements to
 // be used to create the User. It may optionally supply a proposed User Id.
 type CreateUser struct {
+	XMLName                xml.Name
 	InitiationHeader       *InitiationHeader
 	MandatedAction         *MandatedAction
 	UserId                 *UserId
@@ -365,6 +378,7 @@ type CreateUser struct {
 // CreateUserResponse represents a response to CreateUser request. The
 // responding application creates a record for a user and returns a User Id.
 type CreateUserResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	UserId         *UserId
@@ -376,6 +390,7 @@ type CreateUserResponse struct {
 // User. The initiating application provides the type of update and fiscal
 // details about the update it wants done.
 type CreateUserFiscalTransaction struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	MandatedAction               *MandatedAction
 	UserId                       *UserId
@@ -388,6 +403,7 @@ type CreateUserFiscalTransaction struct {
 // CreateUserFiscalTransaction request. The responding application creates
 // fiscal transaction data for the User.
 type CreateUserFiscalTransactionResponse struct {
+	XMLName                      xml.Name
 	ResponseHeader               *ResponseHeader
 	Problem                      []Problem
 	UserId                       UserId
@@ -396,7 +412,7 @@ type CreateUserFiscalTransactionResponse struct {
 }
 
 // DeleteItem represents a request that asks that the responding application
-//delete an item record. This may also result in deletion of the associated
+// delete an item record. This may also result in deletion of the associated
 // bibliographic record, depending on the rules of the responding application.
 // The purpose of this service is to enable applications to remove unnecessary
 // items in weeding and binding situations as well as cleaning up after
@@ -404,6 +420,7 @@ type CreateUserFiscalTransactionResponse struct {
 // identifier and the responder is not required to return any data other than
 // an acknowledgement or an error.
 type DeleteItem struct {
+	XMLName          xml.Name
 	InitiationHeader *InitiationHeader
 	MandatedAction   *MandatedAction
 	ItemId           ItemId
@@ -414,6 +431,7 @@ type DeleteItem struct {
 // responding application deletes the item specified by the initiating
 // application.
 type DeleteItemResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	ItemId         *ItemId
@@ -427,6 +445,7 @@ type DeleteItemResponse struct {
 // responder is not required to return any data other than an acknowledgement
 // or an error.
 type DeleteUser struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	MandatedAction      *MandatedAction
 	UserId              *UserId
@@ -438,6 +457,7 @@ type DeleteUser struct {
 // responding application deletes the user record specified by the initiating
 // application.
 type DeleteUserResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	UserId         *UserId
@@ -449,6 +469,7 @@ type DeleteUserResponse struct {
 // Unique Item Id and may optionally provide other details associated with the
 // check in.
 type ItemCheckedIn struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	UserId                       *UserId
 	ItemId                       ItemId
@@ -462,6 +483,7 @@ type ItemCheckedIn struct {
 // ItemCheckedInResponse represents a response to a ItemCheckedIn request. The
 // responding application replies that it understood the notification message.
 type ItemCheckedInResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -472,6 +494,7 @@ type ItemCheckedInResponse struct {
 // provides the Item Id and User Id involved in the check out, and may
 // optionally provide other details associated with the check out.
 type ItemCheckedOut struct {
+	XMLName                        xml.Name
 	InitiationHeader               *InitiationHeader
 	UserId                         UserId
 	ItemId                         ItemId
@@ -492,6 +515,7 @@ type ItemCheckedOut struct {
 // The responding application replies that it understood the notification
 // message.
 type ItemCheckedOutResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -502,6 +526,7 @@ type ItemCheckedOutResponse struct {
 // Item Id and details about the Item, and may optionally provide other details
 // associated with the Item.
 type ItemCreated struct {
+	XMLName                  xml.Name
 	InitiationHeader         *InitiationHeader
 	ItemId                   ItemId
 	RequestId                *RequestId
@@ -520,6 +545,7 @@ type ItemCreated struct {
 // The responding application replies that it understood the notification
 // message.
 type ItemCreatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -530,6 +556,7 @@ type ItemCreatedResponse struct {
 // provides the Item Id, and may optionally provide the User Id and other data
 // related to cancellation of the recall.
 type ItemRecallCancelled struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	UserId                       *UserId
 	ItemId                       ItemId
@@ -543,6 +570,7 @@ type ItemRecallCancelled struct {
 // request. The responding application replies that it understood the
 // notification message
 type ItemRecallCancelledResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -553,6 +581,7 @@ type ItemRecallCancelledResponse struct {
 // Id of the recalled item and a new due date, and may optionally provide other
 // details associated with the recall.
 type ItemRecalled struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	UserId              *UserId
 	ItemId              ItemId
@@ -566,6 +595,7 @@ type ItemRecalled struct {
 // ItemRecalledResponse represents a response to an ItemRecalled request. The
 // responding application replies that it understood the notification message.
 type ItemRecalledResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -577,6 +607,7 @@ type ItemRecalledResponse struct {
 // The initiating application may optionally provide other details associated
 // with the receipt of the Item.
 type ItemReceived struct {
+	XMLName            xml.Name
 	InitiationHeader   *InitiationHeader
 	ItemId             ItemId
 	UserId             *UserId
@@ -590,6 +621,7 @@ type ItemReceived struct {
 // ItemReceivedResponse represents a response to an ItemReceived request. The
 // responding application replies that it understood the notification message.
 type ItemReceivedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -600,6 +632,7 @@ type ItemReceivedResponse struct {
 // Id and the new due date, and may optionally supply other details associated
 // with the renewal.
 type ItemRenewed struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	UserId                       *UserId
 	ItemId                       ItemId
@@ -614,6 +647,7 @@ type ItemRenewed struct {
 // ItemRenewedResponse represents a response to an ItemRenewed request. The
 // responding application replies that it understood the notification message.
 type ItemRenewedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -625,6 +659,7 @@ type ItemRenewedResponse struct {
 // type of the request being cancelled. It may optionally provide other details
 // associated with the request being cancelled.
 type ItemRequestCancelled struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	UserId                       UserId
 	RequestId                    RequestId
@@ -641,6 +676,7 @@ type ItemRequestCancelled struct {
 // request. The responding application replies that it understood the notification
 // message.
 type ItemRequestCancelledResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -655,6 +691,7 @@ type ItemRequestCancelledResponse struct {
 // message must include that element in both the delete and add sections of the
 // message. Other data may also be provided.
 type ItemRequestUpdated struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	UserId              UserId
 	ItemId              ItemId
@@ -671,6 +708,7 @@ type ItemRequestUpdated struct {
 // request. The responding application replies that it understood the
 // notification message.
 type ItemRequestUpdatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -682,6 +720,7 @@ type ItemRequestUpdatedResponse struct {
 // Bibliographic Id. It also provides the User Id and the type of request being
 // made. It may also optionally provide other details related to the request.
 type ItemRequested struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	UserId              UserId
 	ItemId              *ItemId
@@ -704,6 +743,7 @@ type ItemRequested struct {
 // ItemRequestedResponse represents a response to a ItemRequested request. The
 // responding application replies that it understood the notification message.
 type ItemRequestedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -716,6 +756,7 @@ type ItemRequestedResponse struct {
 // message. The initiating application may also provide other details
 // associated with shipment of the Item.
 type ItemShipped struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	RequestId           RequestId
 	ItemId              *ItemId
@@ -730,6 +771,7 @@ type ItemShipped struct {
 // ItemShippedResponse represents a response to an ItemShipped request. The
 // responding application replies that it understood the notification message.
 type ItemShippedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -743,6 +785,7 @@ type ItemShippedResponse struct {
 // that element in both the delete and add sections of the message. This
 // service may not be used to report changes to circulation status.
 type ItemUpdated struct {
+	XMLName          xml.Name
 	InitiationHeader *InitiationHeader
 	ItemId           ItemId
 	DeleteItemFields *DeleteItemFields
@@ -753,6 +796,7 @@ type ItemUpdated struct {
 // ItemUpdatedResponse represents a response to an ItemUpdated request. The
 // responding application replies that it understood the notification message.
 type ItemUpdatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -763,6 +807,7 @@ type ItemUpdatedResponse struct {
 // provides the Id of the Agency and the list of elements for which data is
 // requested.
 type LookupAgency struct {
+	XMLName           xml.Name
 	InitiationHeader  *InitiationHeader
 	AgencyId          SchemeValue
 	AgencyElementType []SchemeValue
@@ -772,6 +817,7 @@ type LookupAgency struct {
 // LookupAgencyResponse represents a response to a LookupAgency request. The
 // responding application returns the requested data to the initiating application.
 type LookupAgencyResponse struct {
+	XMLName                         xml.Name
 	ResponseHeader                  *ResponseHeader
 	Problem                         []Problem
 	AgencyId                        SchemeValue
@@ -788,6 +834,7 @@ type LookupAgencyResponse struct {
 // the responding application. The initiator provides the Id of the Item and a
 // list of elements for which data is requested.
 type LookupItem struct {
+	XMLName                  xml.Name
 	InitiationHeader         *InitiationHeader
 	ItemId                   *ItemId
 	RequestId                *RequestId
@@ -801,6 +848,7 @@ type LookupItem struct {
 // responding application returns the requested data to the initiating
 // application.
 type LookupItemResponse struct {
+	XMLName            xml.Name
 	ResponseHeader     *ResponseHeader
 	Problem            []Problem
 	RequestId          RequestId
@@ -816,6 +864,7 @@ type LookupItemResponse struct {
 // known to the responding application. The initiator provides the Id of the
 // Request and a list of elements for which data is requested.
 type LookupRequest struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	UserId              *UserId
 	AuthenticationInput []AuthenticationInput
@@ -832,6 +881,7 @@ type LookupRequest struct {
 // responding application returns the requested data to the initiating
 // application.
 type LookupRequestResponse struct {
+	XMLName               xml.Name
 	ResponseHeader        *ResponseHeader
 	Problem               []Problem
 	RequestId             RequestId
@@ -893,6 +943,7 @@ type LookupUserResponse struct {
 // the Item. The initiating application may also request data about the User
 // and/or Item involved with this recall.
 type RecallItem struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	MandatedAction      *MandatedAction
 	ItemId              ItemId
@@ -908,6 +959,7 @@ type RecallItem struct {
 // action. It may supply the new date on which the Item is now due. It may
 // supply the data elements requested.
 type RecallItemResponse struct {
+	XMLName                      xml.Name
 	ResponseHeader               *ResponseHeader
 	Problem                      []Problem
 	ItemId                       ItemId
@@ -968,6 +1020,7 @@ type RenewItemResponse struct {
 // borrowed. The initiating application may also request data about the User
 // and/or Item involved with this status change.
 type ReportCirculationStatusChange struct {
+	XMLName                   xml.Name
 	InitiationHeader          *InitiationHeader
 	MandatedAction            *MandatedAction
 	ItemId                    ItemId
@@ -987,6 +1040,7 @@ type ReportCirculationStatusChange struct {
 // Item as specified by the initiator. It may also supply the data elements
 // requested.
 type ReportCirculationStatusChangeResponse struct {
+	XMLName            xml.Name
 	ResponseHeader     *ResponseHeader
 	Problem            []Problem
 	ItemId             ItemId
@@ -1057,6 +1111,7 @@ type RequestItemResponse struct {
 // provide a date on which it desires the notice be sent, and the content of
 // the notice.
 type SendUserNotice struct {
+	XMLName           xml.Name
 	InitiationHeader  *InitiationHeader
 	MandatedAction    *MandatedAction
 	UserId            UserId
@@ -1069,6 +1124,7 @@ type SendUserNotice struct {
 // The responding application agrees to send a notice and may provide either
 // the date the notice was sent or the date on which it will send the notice.
 type SendUserNoticeResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	UserId         UserId
@@ -1083,6 +1139,7 @@ type SendUserNoticeResponse struct {
 // had never occurred. This service is typically used by self-check
 // applications.
 type UndoCheckOutItem struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	MandatedAction      *MandatedAction
 	ItemId              ItemId
@@ -1095,6 +1152,7 @@ type UndoCheckOutItem struct {
 // UndoCheckOutItemResponse represents a response to a UndoCheckOutItem
 // request. The immediately preceding check out is undone.
 type UndoCheckOutItemResponse struct {
+	XMLName                      xml.Name
 	ResponseHeader               *ResponseHeader
 	Problem                      []Problem
 	ItemId                       ItemId
@@ -1110,6 +1168,7 @@ type UndoCheckOutItemResponse struct {
 // for an existing element, it must include that element in both the delete and
 // add sections of the message.
 type UpdateAgency struct {
+	XMLName            xml.Name
 	InitiationHeader   *InitiationHeader
 	MandatedAction     *MandatedAction
 	AgencyId           SchemeValue
@@ -1122,6 +1181,7 @@ type UpdateAgency struct {
 // responding application updates data about the Agency as specified by the
 // initiating application.
 type UpdateAgencyResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	AgencyId       *SchemeValue
@@ -1132,6 +1192,7 @@ type UpdateAgencyResponse struct {
 // update the circulation status of an Item. The initiating application
 // provides the Id of the item Id and the new circulation status.
 type UpdateCirculationStatus struct {
+	XMLName           xml.Name
 	InitiationHeader  *InitiationHeader
 	MandatedAction    *MandatedAction
 	ItemId            ItemId
@@ -1143,6 +1204,7 @@ type UpdateCirculationStatus struct {
 // UpdateCirculationStats request. The responding application updates the
 // circulation status as specified by the initiating application.
 type UpdateCirculationStatusResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	ItemId         *ItemId
@@ -1157,6 +1219,7 @@ type UpdateCirculationStatusResponse struct {
 // sections of the message. This service cannot be used to change the
 // circulation status of an Item.
 type UpdateItem struct {
+	XMLName          xml.Name
 	InitiationHeader *InitiationHeader
 	MandatedAction   *MandatedAction
 	ItemId           ItemId
@@ -1169,6 +1232,7 @@ type UpdateItem struct {
 // responding application updates data about the Item as specified by the
 // initiating application.
 type UpdateItemResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	ItemId         *ItemId
@@ -1182,6 +1246,7 @@ type UpdateItemResponse struct {
 // initiating application is changing the value for an existing element, it
 // must include that element in both the delete andadd sections of the message.
 type UpdateRequestItem struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	MandatedAction      *MandatedAction
 	UserId              *UserId
@@ -1200,6 +1265,7 @@ type UpdateRequestItem struct {
 // request. The responding application updates the Item request as specified by
 // the initiating application.
 type UpdateRequestItemResponse struct {
+	XMLName                        xml.Name
 	ResponseHeader                 *ResponseHeader
 	Problem                        []Problem
 	RequiredFeeAmount              *RequiredFeeAmount
@@ -1221,8 +1287,9 @@ type UpdateRequestItemResponse struct {
 // with the value for each element and/or elements to be added with the value
 // for each element. If the initiating application is changing the value for an
 // existing element, it must include that element in both the delete and add
-//sections of the message.
+// sections of the message.
 type UpdateUser struct {
+	XMLName             xml.Name
 	InitiationHeader    *InitiationHeader
 	MandatedAction      *MandatedAction
 	UserId              *UserId
@@ -1236,6 +1303,7 @@ type UpdateUser struct {
 // responding application updates data about the User as specified by the
 // initiating application.
 type UpdateUserResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	UserId         *UserId
@@ -1247,6 +1315,7 @@ type UpdateUserResponse struct {
 // provides Name Information and the User Id and may optionally provide
 // additional data about the new User.
 type UserCreated struct {
+	XMLName                xml.Name
 	InitiationHeader       *InitiationHeader
 	UserId                 UserId
 	NameInformation        NameInformation
@@ -1261,6 +1330,7 @@ type UserCreated struct {
 // UserCreatedResponse represents a response to an UserCreated request. The
 // responding application replies that it understood the notification message.
 type UserCreatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -1270,6 +1340,7 @@ type UserCreatedResponse struct {
 // application that a new fiscal transaction has been applied to a User’s
 // fiscal account.
 type UserFiscalTransactionCreated struct {
+	XMLName                      xml.Name
 	InitiationHeader             *InitiationHeader
 	UserId                       UserId
 	FiscalTransactionInformation FiscalTransactionInformation
@@ -1280,6 +1351,7 @@ type UserFiscalTransactionCreated struct {
 // UserFiscalTransactionCreated request. The responding application replies
 // that it understood the notification message.
 type UserFiscalTransactionCreatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -1289,6 +1361,7 @@ type UserFiscalTransactionCreatedResponse struct {
 // that a notice has been sent to a User. The initiating application provides
 // the User Id, the type of notice, and details about the notice.
 type UserNoticeSent struct {
+	XMLName           xml.Name
 	InitiationHeader  *InitiationHeader
 	UserId            UserId
 	DateSent          string // xs:dateTime
@@ -1300,6 +1373,7 @@ type UserNoticeSent struct {
 // The responding application replies that it understood the notification
 // message.
 type UserNoticeSentResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -1312,6 +1386,7 @@ type UserNoticeSentResponse struct {
 // element. If the value for an existing element has changed, the message must
 // include that element in both the delete and add sections of the message.
 type UserUpdated struct {
+	XMLName          xml.Name
 	InitiationHeader *InitiationHeader
 	UserId           UserId
 	DeleteUserFields *DeleteUserFields
@@ -1322,6 +1397,7 @@ type UserUpdated struct {
 // UserUpdatedResponse represents a response to an UserUpdated request. The
 // responding application replies that it understood the notification message.
 type UserUpdatedResponse struct {
+	XMLName        xml.Name
 	ResponseHeader *ResponseHeader
 	Problem        []Problem
 	Ext            *Ext
@@ -2034,18 +2110,110 @@ type UserPrivilegeStatus struct {
 // TODO complete theese, missing from autogenerated output
 type UserFiscalAccount struct{}
 
-func (r LookupUser) Type() requestType        { return TypeLookupUser }
-func (r RequestItem) Type() requestType       { return TypeRequestItem }
-func (r CheckOutItem) Type() requestType      { return TypeCheckOutItem }
-func (r CheckInItem) Type() requestType       { return TypeCheckInItem }
 func (r AcceptItem) Type() requestType        { return TypeAcceptItem }
-func (r RenewItem) Type() requestType         { return TypeRenewItem }
+func (r AgencyCreated) Type() requestType     { return TypeAgencyCreated }
+func (r AgencyUpdated) Type() requestType     { return TypeAgencyUpdated }
+func (r CancelRecallItem) Type() requestType  { return TypeCancelRecallItem }
 func (r CancelRequestItem) Type() requestType { return TypeCancelRequestItem }
+func (r CheckInItem) Type() requestType       { return TypeCheckInItem }
+func (r CheckOutItem) Type() requestType      { return TypeCheckOutItem }
+func (r CirculationStatusChangeReported) Type() requestType {
+	return TypeCirculationStatusChangeReported
+}
+func (r CirculationStatusUpdated) Type() requestType      { return TypeCirculationStatusUpdated }
+func (r CreateAgency) Type() requestType                  { return TypeCreateAgency }
+func (r CreateItem) Type() requestType                    { return TypeCreateItem }
+func (r CreateUser) Type() requestType                    { return TypeCreateUser }
+func (r CreateUserFiscalTransaction) Type() requestType   { return TypeCreateUserFiscalTransaction }
+func (r DeleteItem) Type() requestType                    { return TypeDeleteItem }
+func (r DeleteUser) Type() requestType                    { return TypeDeleteUser }
+func (r ItemCheckedIn) Type() requestType                 { return TypeItemCheckedIn }
+func (r ItemCheckedOut) Type() requestType                { return TypeItemCheckedOut }
+func (r ItemCreated) Type() requestType                   { return TypeItemCreated }
+func (r ItemRecallCancelled) Type() requestType           { return TypeItemRecallCancelled }
+func (r ItemRecalled) Type() requestType                  { return TypeItemRecalled }
+func (r ItemReceived) Type() requestType                  { return TypeItemReceived }
+func (r ItemRenewed) Type() requestType                   { return TypeItemRenewed }
+func (r ItemRequestCancelled) Type() requestType          { return TypeItemRequestCancelled }
+func (r ItemRequestUpdated) Type() requestType            { return TypeItemRequestUpdated }
+func (r ItemRequested) Type() requestType                 { return TypeItemRequested }
+func (r ItemShipped) Type() requestType                   { return TypeItemShipped }
+func (r ItemUpdated) Type() requestType                   { return TypeItemUpdated }
+func (r LookupAgency) Type() requestType                  { return TypeLookupAgency }
+func (r LookupItem) Type() requestType                    { return TypeLookupItem }
+func (r LookupRequest) Type() requestType                 { return TypeLookupRequest }
+func (r LookupUser) Type() requestType                    { return TypeLookupUser }
+func (r RecallItem) Type() requestType                    { return TypeRecallItem }
+func (r RenewItem) Type() requestType                     { return TypeRenewItem }
+func (r ReportCirculationStatusChange) Type() requestType { return TypeReportCirculationStatusChange }
+func (r RequestItem) Type() requestType                   { return TypeRequestItem }
+func (r SendUserNotice) Type() requestType                { return TypeSendUserNotice }
+func (r UndoCheckOutItem) Type() requestType              { return TypeUndoCheckOutItem }
+func (r UpdateAgency) Type() requestType                  { return TypeUpdateAgency }
+func (r UpdateCirculationStatus) Type() requestType       { return TypeUpdateCirculationStatus }
+func (r UpdateItem) Type() requestType                    { return TypeUpdateItem }
+func (r UpdateRequestItem) Type() requestType             { return TypeUpdateRequestItem }
+func (r UpdateUser) Type() requestType                    { return TypeUpdateUser }
+func (r UserCreated) Type() requestType                   { return TypeUserCreated }
+func (r UserFiscalTransactionCreated) Type() requestType  { return TypeUserFiscalTransactionCreated }
+func (r UserNoticeSent) Type() requestType                { return TypeUserNoticeSent }
+func (r UserUpdated) Type() requestType                   { return TypeUserUpdated }
 
-func (r LookupUserResponse) Type() responseType        { return TypeLookupUserResponse }
-func (r RequestItemResponse) Type() responseType       { return TypeRequestItemResponse }
-func (r CheckOutItemResponse) Type() responseType      { return TypeCheckOutItemResponse }
-func (r CheckInItemResponse) Type() responseType       { return TypeCheckInItemResponse }
-func (r RenewItemResponse) Type() responseType         { return TypeRenewItemResponse }
-func (r CancelRequestItemResponse) Type() responseType { return TypeCancelRequestItemResponse }
 func (r AcceptItemResponse) Type() responseType        { return TypeAcceptItemResponse }
+func (r AgencyCreatedResponse) Type() responseType     { return TypeAgencyCreatedResponse }
+func (r AgencyUpdatedResponse) Type() responseType     { return TypeAgencyUpdatedResponse }
+func (r CancelRecallItemResponse) Type() responseType  { return TypeCancelRecallItemResponse }
+func (r CancelRequestItemResponse) Type() responseType { return TypeCancelRequestItemResponse }
+func (r CheckInItemResponse) Type() responseType       { return TypeCheckInItemResponse }
+func (r CheckOutItemResponse) Type() responseType      { return TypeCheckOutItemResponse }
+func (r CirculationStatusChangeReportedResponse) Type() responseType {
+	return TypeCirculationStatusChangeReportedResponse
+}
+func (r CirculationStatusUpdatedResponse) Type() responseType {
+	return TypeCirculationStatusUpdatedResponse
+}
+func (r CreateAgencyResponse) Type() responseType { return TypeCreateAgencyResponse }
+func (r CreateItemResponse) Type() responseType   { return TypeCreateItemResponse }
+func (r CreateUserResponse) Type() responseType   { return TypeCreateUserResponse }
+func (r CreateUserFiscalTransactionResponse) Type() responseType {
+	return TypeCreateUserFiscalTransactionResponse
+}
+func (r DeleteItemResponse) Type() responseType           { return TypeDeleteItemResponse }
+func (r DeleteUserResponse) Type() responseType           { return TypeDeleteUserResponse }
+func (r ItemCheckedInResponse) Type() responseType        { return TypeItemCheckedInResponse }
+func (r ItemCheckedOutResponse) Type() responseType       { return TypeItemCheckedOutResponse }
+func (r ItemCreatedResponse) Type() responseType          { return TypeItemCreatedResponse }
+func (r ItemRecallCancelledResponse) Type() responseType  { return TypeItemRecallCancelledResponse }
+func (r ItemRecalledResponse) Type() responseType         { return TypeItemRecalledResponse }
+func (r ItemReceivedResponse) Type() responseType         { return TypeItemReceivedResponse }
+func (r ItemRenewedResponse) Type() responseType          { return TypeItemRenewedResponse }
+func (r ItemRequestCancelledResponse) Type() responseType { return TypeItemRequestCancelledResponse }
+func (r ItemRequestUpdatedResponse) Type() responseType   { return TypeItemRequestUpdatedResponse }
+func (r ItemRequestedResponse) Type() responseType        { return TypeItemRequestedResponse }
+func (r ItemShippedResponse) Type() responseType          { return TypeItemShippedResponse }
+func (r ItemUpdatedResponse) Type() responseType          { return TypeItemUpdatedResponse }
+func (r LookupAgencyResponse) Type() responseType         { return TypeLookupAgencyResponse }
+func (r LookupItemResponse) Type() responseType           { return TypeLookupItemResponse }
+func (r LookupRequestResponse) Type() responseType        { return TypeLookupRequestResponse }
+func (r LookupUserResponse) Type() responseType           { return TypeLookupUserResponse }
+func (r RecallItemResponse) Type() responseType           { return TypeRecallItemResponse }
+func (r RenewItemResponse) Type() responseType            { return TypeRenewItemResponse }
+func (r ReportCirculationStatusChangeResponse) Type() responseType {
+	return TypeReportCirculationStatusChangeResponse
+}
+func (r RequestItemResponse) Type() responseType      { return TypeRequestItemResponse }
+func (r SendUserNoticeResponse) Type() responseType   { return TypeSendUserNoticeResponse }
+func (r UndoCheckOutItemResponse) Type() responseType { return TypeUndoCheckOutItemResponse }
+func (r UpdateAgencyResponse) Type() responseType     { return TypeUpdateAgencyResponse }
+func (r UpdateCirculationStatusResponse) Type() responseType {
+	return TypeUpdateCirculationStatusResponse
+}
+func (r UpdateItemResponse) Type() responseType        { return TypeUpdateItemResponse }
+func (r UpdateRequestItemResponse) Type() responseType { return TypeUpdateRequestItemResponse }
+func (r UpdateUserResponse) Type() responseType        { return TypeUpdateUserResponse }
+func (r UserCreatedResponse) Type() responseType       { return TypeUserCreatedResponse }
+func (r UserFiscalTransactionCreatedResponse) Type() responseType {
+	return TypeUserFiscalTransactionCreatedResponse
+}
+func (r UserNoticeSentResponse) Type() responseType { return TypeUserNoticeSentResponse }
+func (r UserUpdatedResponse) Type() responseType    { return TypeUserUpdatedResponse }
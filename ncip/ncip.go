@@ -22,7 +22,7 @@ type requestType int
 const (
 	illegalRequest requestType = iota
 	TypeAcceptItem
-	TypeAcceptItemResponsegencyCreated
+	TypeAgencyCreated
 	TypeAgencyUpdated
 	TypeCancelRecallItem
 	TypeCancelRequestItem
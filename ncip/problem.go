@@ -0,0 +1,182 @@
+package ncip
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Schemes of the problem types defined by NCIP.
+const (
+	SchemeGeneralProcessingError = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/generalprocessingerror.scm"
+	SchemeMessagingError         = "http://www.niso.org/ncip/v1_0/schemes/messagingerrortype/messagingerrortype.scm"
+	SchemeCheckOutItemError      = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/checkoutitemprocessingerror.scm"
+	SchemeCheckInItemError       = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/checkinitemprocessingerror.scm"
+	SchemeRenewItemError         = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/renewitemprocessingerror.scm"
+	SchemeRequestItemError       = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/requestitemprocessingerror.scm"
+	SchemeCancelRequestItemError = "http://www.niso.org/ncip/v1_0/schemes/processingerrortype/cancelrequestitemprocessingerror.scm"
+)
+
+// Problems of the types defined by NCIP. They are errors which can be
+// matched with errors.Is, and given details with Problem.At and
+// Problem.WithDetail:
+//
+//	return nil, ncip.ErrUnknownUser.At("UserIdentifierValue", id)
+var (
+	// General processing errors, which may occur in any service.
+	ErrAgencyAuthenticationFailed = problem(SchemeGeneralProcessingError, "Agency Authentication Failed")
+	ErrInvalidAmount              = problem(SchemeGeneralProcessingError, "Invalid Amount")
+	ErrInvalidDate                = problem(SchemeGeneralProcessingError, "Invalid Date")
+	ErrTemporaryProcessingFailure = problem(SchemeGeneralProcessingError, "Temporary Processing Failure")
+	ErrUnauthorizedService        = problem(SchemeGeneralProcessingError, "Unauthorized Service For Agency")
+	ErrUnknownAgency              = problem(SchemeGeneralProcessingError, "Unknown Agency")
+	ErrUnknownItem                = problem(SchemeGeneralProcessingError, "Unknown Item")
+	ErrUnknownRequest             = problem(SchemeGeneralProcessingError, "Unknown Request")
+	ErrUnknownScheme              = problem(SchemeGeneralProcessingError, "Unknown Scheme")
+	ErrUnknownSchemeValue         = problem(SchemeGeneralProcessingError, "Unknown Value From Known Scheme")
+	ErrUnknownUser                = problem(SchemeGeneralProcessingError, "Unknown User")
+	ErrUnsupportedService         = problem(SchemeGeneralProcessingError, "Unsupported Service")
+	ErrUserAuthenticationFailed   = problem(SchemeGeneralProcessingError, "User Authentication Failed")
+
+	// Messaging errors.
+	ErrInvalidMessageSyntax = problem(SchemeMessagingError, "Invalid Message Syntax Error")
+
+	// CheckOutItem errors.
+	ErrItemDoesNotCirculate     = problem(SchemeCheckOutItemError, "Item Does Not Circulate")
+	ErrMaximumCheckOutsExceeded = problem(SchemeCheckOutItemError, "Maximum Check Outs Exceeded")
+	ErrResourceCannotBeProvided = problem(SchemeCheckOutItemError, "Resource Cannot Be Provided")
+	ErrUserBlocked              = problem(SchemeCheckOutItemError, "User Blocked")
+	ErrUserIneligibleToCheckOut = problem(SchemeCheckOutItemError, "User Ineligible To Check Out This Item")
+
+	// CheckInItem errors.
+	ErrItemNotCheckedOut = problem(SchemeCheckInItemError, "Item Not Checked Out")
+
+	// RenewItem errors.
+	ErrItemNotRenewable        = problem(SchemeRenewItemError, "Item Not Renewable")
+	ErrMaximumRenewalsExceeded = problem(SchemeRenewItemError, "Maximum Renewals Exceeded")
+
+	// RequestItem errors.
+	ErrDuplicateRequest        = problem(SchemeRequestItemError, "Duplicate Request")
+	ErrUserIneligibleToRequest = problem(SchemeRequestItemError, "User Ineligible To Request This Item")
+
+	// CancelRequestItem errors.
+	ErrRequestAlreadyProcessed = problem(SchemeCancelRequestItemError, "Request Already Processed")
+)
+
+func problem(scheme, value string) Problem {
+	return Problem{ProblemType: SchemeValue{Scheme: scheme, Value: value}}
+}
+
+// At returns a copy of the problem, with the element, and its value, which
+// caused the problem.
+func (p Problem) At(element, value string) Problem {
+	p.ProblemElement = element
+	p.ProblemValue = value
+	return p
+}
+
+// WithDetail returns a copy of the problem, with the detail describing it.
+func (p Problem) WithDetail(detail string) Problem {
+	p.ProblemDetail = detail
+	return p
+}
+
+// Error returns the problem type, with any detail, element and value.
+func (p Problem) Error() string {
+	return "ncip: " + p.text()
+}
+
+// Is reports whether the target is a Problem of the same type. Problems
+// without a scheme match problems of any scheme with the same value.
+func (p Problem) Is(target error) bool {
+	t, ok := target.(Problem)
+	if !ok || t.ProblemType.Value != p.ProblemType.Value {
+		return false
+	}
+	return t.ProblemType.Scheme == "" || p.ProblemType.Scheme == "" ||
+		t.ProblemType.Scheme == p.ProblemType.Scheme
+}
+
+func (p Problem) text() string {
+	s := p.ProblemType.Value
+	if p.ProblemDetail != "" {
+		s += ": " + p.ProblemDetail
+	}
+	if p.ProblemElement != "" {
+		s += " (" + p.ProblemElement
+		if p.ProblemValue != "" {
+			s += " " + p.ProblemValue
+		}
+		s += ")"
+	}
+	return s
+}
+
+// ProblemError is returned by a Client when the responder reports one or
+// more Problems processing a request. It matches each of the Problems with
+// errors.Is:
+//
+//	if errors.Is(err, ncip.ErrMaximumRenewalsExceeded) { ... }
+type ProblemError struct {
+	// Problems are the problems reported.
+	Problems []Problem
+
+	// Response is the response with the Problems, or nil if they were not
+	// reported in a response to the request.
+	Response Response
+}
+
+func (e *ProblemError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.text()
+	}
+	return "ncip: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the Problems.
+func (e *ProblemError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, p := range e.Problems {
+		errs[i] = p
+	}
+	return errs
+}
+
+// problems returns the Problems of the error: the Problem or *ProblemError
+// it is or wraps, or else a Temporary Processing Failure with the error as
+// detail.
+func problems(err error) []Problem {
+	var pe *ProblemError
+	if errors.As(err, &pe) && len(pe.Problems) > 0 {
+		return pe.Problems
+	}
+	var p Problem
+	if errors.As(err, &p) {
+		return []Problem{p}
+	}
+	return []Problem{ErrTemporaryProcessingFailure.WithDetail(err.Error())}
+}
+
+// ErrorResponse returns the response to the request reporting the error,
+// for Handlers to report problems: a response of the type corresponding to
+// the request, addressed to the initiator, with the Problems of the error.
+// If the error is not a Problem or *ProblemError, it is reported as a
+// Temporary Processing Failure.
+func ErrorResponse(req Request, err error) Response {
+	resp := responseTypes[requestNames[req.Type()]+"Response"]()
+	rv := reflect.ValueOf(resp).Elem()
+	rv.FieldByName("Problem").Set(reflect.ValueOf(problems(err)))
+
+	qv := reflect.ValueOf(req)
+	if qv.Kind() == reflect.Ptr {
+		qv = qv.Elem()
+	}
+	if h, ok := qv.FieldByName("InitiationHeader").Interface().(*InitiationHeader); ok && h != nil {
+		rv.FieldByName("ResponseHeader").Set(reflect.ValueOf(&ResponseHeader{
+			FromAgencyId: FromAgencyId{AgencyId: h.ToAgencyId.AgencyId},
+			ToAgencyId:   ToAgencyId{AgencyId: h.FromAgencyId.AgencyId},
+		}))
+	}
+	return resp
+}
@@ -0,0 +1,101 @@
+package ncip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProblemIs(t *testing.T) {
+	tests := []struct {
+		err    error
+		target error
+		want   bool
+	}{
+		{ErrUnknownUser, ErrUnknownUser, true},
+		{ErrUnknownUser.At("UserIdentifierValue", "p1"), ErrUnknownUser, true},
+		{fmt.Errorf("lookup: %w", ErrUnknownUser.WithDetail("x")), ErrUnknownUser, true},
+		{ErrUnknownUser, ErrUnknownItem, false},
+		// Problems without a scheme match any scheme.
+		{Problem{ProblemType: SchemeValue{Value: "Unknown User"}}, ErrUnknownUser, true},
+		{Problem{ProblemType: SchemeValue{Scheme: "http://example.org/local.scm", Value: "Unknown User"}}, ErrUnknownUser, false},
+		{&ProblemError{Problems: []Problem{ErrUserBlocked, ErrMaximumCheckOutsExceeded}}, ErrMaximumCheckOutsExceeded, true},
+		{&ProblemError{Problems: []Problem{ErrUserBlocked}}, ErrUnknownUser, false},
+		{errors.New("Unknown User"), ErrUnknownUser, false},
+	}
+	for _, test := range tests {
+		if got := errors.Is(test.err, test.target); got != test.want {
+			t.Errorf("errors.Is(%v, %v) => %v; want %v", test.err, test.target, got, test.want)
+		}
+	}
+}
+
+func TestProblemError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{ErrUnknownUser, "ncip: Unknown User"},
+		{ErrUnknownUser.At("UserIdentifierValue", "p1").WithDetail("not registered"), "ncip: Unknown User: not registered (UserIdentifierValue p1)"},
+		{&ProblemError{Problems: []Problem{ErrUserBlocked, ErrInvalidDate.At("DesiredDateDue", "")}}, "ncip: User Blocked; Invalid Date (DesiredDateDue)"},
+	} {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Error() => %q; want %q", got, test.want)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	req := &CheckOutItem{
+		InitiationHeader: &InitiationHeader{
+			FromAgencyId: FromAgencyId{AgencyId: SchemeValue{Value: "API9999"}},
+			ToAgencyId:   ToAgencyId{AgencyId: SchemeValue{Value: "LEHI"}},
+		},
+	}
+	tests := []struct {
+		err  error
+		want []Problem
+	}{
+		{ErrUserBlocked, []Problem{ErrUserBlocked}},
+		{fmt.Errorf("checkout: %w", ErrItemDoesNotCirculate.At("ItemIdentifierValue", "15")), []Problem{ErrItemDoesNotCirculate.At("ItemIdentifierValue", "15")}},
+		{&ProblemError{Problems: []Problem{ErrUserBlocked, ErrUnknownItem}}, []Problem{ErrUserBlocked, ErrUnknownItem}},
+		{errors.New("database down"), []Problem{ErrTemporaryProcessingFailure.WithDetail("database down")}},
+	}
+	for _, test := range tests {
+		resp, ok := ErrorResponse(req, test.err).(*CheckOutItemResponse)
+		if !ok {
+			t.Fatalf("ErrorResponse(%v) => %T; want *CheckOutItemResponse", test.err, resp)
+		}
+		if !reflect.DeepEqual(resp.Problem, test.want) {
+			t.Errorf("ErrorResponse(%v) => %+v; want %+v", test.err, resp.Problem, test.want)
+		}
+		if resp.ResponseHeader == nil || resp.ResponseHeader.ToAgencyId.AgencyId.Value != "API9999" {
+			t.Errorf("ErrorResponse(%v) => header %+v; want addressed to API9999", test.err, resp.ResponseHeader)
+		}
+	}
+
+	// The problems are serialised with their schemes, and decoded as errors
+	// matching the problems.
+	var b bytes.Buffer
+	if err := EncodeResponse(&b, ErrorResponse(req, ErrUserBlocked.At("UserIdentifierValue", "p1"))); err != nil {
+		t.Fatal(err)
+	}
+	want := `    <Problem>
+      <ProblemType Scheme="http://www.niso.org/ncip/v1_0/schemes/processingerrortype/checkoutitemprocessingerror.scm">User Blocked</ProblemType>
+      <ProblemElement>UserIdentifierValue</ProblemElement>
+      <ProblemValue>p1</ProblemValue>
+    </Problem>`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("EncodeResponse =>\n%s\nwant it to contain:\n%s", b.String(), want)
+	}
+	resp, err := DecodeResponse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := resp.(*CheckOutItemResponse).Problem; len(p) != 1 || !errors.Is(p[0], ErrUserBlocked) {
+		t.Errorf("decoded problems %v; want User Blocked", p)
+	}
+}
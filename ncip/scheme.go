@@ -0,0 +1,220 @@
+package ncip
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Scheme is a set of values defined for an element, such as the circulation
+// statuses of items, identified by the URI of the scheme.
+type Scheme struct {
+	URI    string
+	Values []string
+}
+
+// Standard schemes defined by NCIP, by the element they apply to.
+var standardSchemes = map[string]Scheme{
+	"AgencyElementType": {
+		URI: "http://www.niso.org/ncip/v1_0/schemes/agencyelementtype/agencyelementtype.scm",
+		Values: []string{
+			"Agency Address Information",
+			"Agency User Privilege Type",
+			"Application Profile Supported Type",
+			"Authentication Prompt",
+			"Consortium Agreement",
+			"Organization Name Information",
+		},
+	},
+	"CirculationStatus": {
+		URI: "http://www.niso.org/ncip/v1_0/imp1/schemes/circulationstatus/circulationstatus.scm",
+		Values: []string{
+			"Available For Pickup",
+			"Available On Shelf",
+			"Circulation Status Undefined",
+			"Claimed Returned Or Never Borrowed",
+			"In Process",
+			"In Transit Between Library Locations",
+			"Lost",
+			"Missing",
+			"Not Available",
+			"On Loan",
+			"On Order",
+			"Waiting To Be Reshelved",
+		},
+	},
+	"ItemElementType": {
+		URI: "http://www.niso.org/ncip/v1_0/schemes/itemelementtype/itemelementtype.scm",
+		Values: []string{
+			"Bibliographic Description",
+			"Circulation Status",
+			"Electronic Resource",
+			"Hold Queue Length",
+			"Item Description",
+			"Item Use Restriction Type",
+			"Location",
+			"Physical Condition",
+			"Security Marker",
+			"Sensitization Flag",
+		},
+	},
+	"RequestedActionType": {
+		URI: "http://www.niso.org/ncip/v1_0/imp1/schemes/requestedactiontype/requestedactiontype.scm",
+		Values: []string{
+			"Circulate",
+			"Circulate And Notify",
+			"Hold For Pickup",
+			"Hold For Pickup And Notify",
+		},
+	},
+	"RequestScopeType": {
+		URI:    "http://www.niso.org/ncip/v1_0/imp1/schemes/requestscopetype/requestscopetype.scm",
+		Values: []string{"Bibliographic Item", "Item"},
+	},
+	"RequestType": {
+		URI:    "http://www.niso.org/ncip/v1_0/imp1/schemes/requesttype/requesttype.scm",
+		Values: []string{"Estimate", "Hold", "Loan", "Recall", "Stack Retrieval"},
+	},
+	"UserElementType": {
+		URI: "http://www.niso.org/ncip/v1_0/schemes/userelementtype/userelementtype.scm",
+		Values: []string{
+			"Authentication Input",
+			"Block Or Trap",
+			"Date Of Birth",
+			"Name Information",
+			"Previous User Id(s)",
+			"User Address Information",
+			"User Id",
+			"User Language",
+			"User Privilege",
+		},
+	},
+}
+
+// schemes is the registry of schemes: the values of each scheme, by URI,
+// by the element they apply to.
+var schemes = struct {
+	sync.RWMutex
+	byElement map[string]map[string]map[string]bool
+}{
+	byElement: make(map[string]map[string]map[string]bool),
+}
+
+func init() {
+	for element, s := range standardSchemes {
+		if err := RegisterScheme(element, s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterScheme registers the scheme as allowed for the element, ex:
+// "CirculationStatus", in addition to the schemes already registered for
+// it. If a scheme with the same URI is registered for the element, the
+// values are added to it, so local values can extend a standard scheme.
+//
+// Once a scheme is registered for an element, Validate reports values of the
+// element which are not in any of its registered schemes. The values of
+// elements without registered schemes are not validated.
+func RegisterScheme(element string, s Scheme) error {
+	if element == "" || s.URI == "" || len(s.Values) == 0 {
+		return errors.New("ncip: scheme must have an element, a URI and values")
+	}
+	schemes.Lock()
+	defer schemes.Unlock()
+	byURI, ok := schemes.byElement[element]
+	if !ok {
+		byURI = make(map[string]map[string]bool)
+		schemes.byElement[element] = byURI
+	}
+	values, ok := byURI[s.URI]
+	if !ok {
+		values = make(map[string]bool)
+		byURI[s.URI] = values
+	}
+	for _, v := range s.Values {
+		values[v] = true
+	}
+	return nil
+}
+
+// Validate checks the values of the elements of the request or response
+// which have registered schemes. A value with a scheme must be in the
+// registered scheme with that URI; a value without a scheme must be in one
+// of the schemes registered for the element.
+//
+// The error returned is a *ProblemError with an "Unknown Scheme" or "Unknown
+// Value From Known Scheme" problem for each invalid value.
+func Validate(msg interface{}) error {
+	var problems []Problem
+	schemes.RLock()
+	validate(reflect.ValueOf(msg), "", &problems)
+	schemes.RUnlock()
+	if len(problems) > 0 {
+		return &ProblemError{Problems: problems}
+	}
+	return nil
+}
+
+var schemeValueType = reflect.TypeOf(SchemeValue{})
+
+// validate checks the values of the element v, and the elements it
+// contains, adding the problems found. The caller must hold the read lock
+// of the registry.
+func validate(v reflect.Value, element string, problems *[]Problem) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			validate(v.Elem(), element, problems)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			validate(v.Index(i), element, problems)
+		}
+	case reflect.Struct:
+		if v.Type() == schemeValueType {
+			if p, ok := checkSchemeValue(element, v.Interface().(SchemeValue)); !ok {
+				*problems = append(*problems, p)
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.Name == "XMLName" || f.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("xml"), ",")[0]
+			if name == "" {
+				name = f.Name
+			}
+			validate(v.Field(i), name, problems)
+		}
+	}
+}
+
+// checkSchemeValue returns the problem with the value of the element, if
+// it is not in a scheme registered for the element.
+func checkSchemeValue(element string, sv SchemeValue) (Problem, bool) {
+	byURI, ok := schemes.byElement[element]
+	if !ok {
+		return Problem{}, true
+	}
+	value := strings.TrimSpace(sv.Value)
+	if sv.Scheme != "" {
+		values, ok := byURI[sv.Scheme]
+		if !ok {
+			return ErrUnknownScheme.At(element, sv.Scheme), false
+		}
+		if !values[value] {
+			return ErrUnknownSchemeValue.At(element, value), false
+		}
+		return Problem{}, true
+	}
+	for _, values := range byURI {
+		if values[value] {
+			return Problem{}, true
+		}
+	}
+	return ErrUnknownSchemeValue.At(element, value), false
+}
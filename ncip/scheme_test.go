@@ -0,0 +1,204 @@
+package ncip
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// restoreSchemes restores the registry of schemes when the test ends.
+func restoreSchemes(t *testing.T) {
+	schemes.Lock()
+	saved := make(map[string]map[string]map[string]bool)
+	for element, byURI := range schemes.byElement {
+		saved[element] = make(map[string]map[string]bool)
+		for uri, values := range byURI {
+			saved[element][uri] = make(map[string]bool)
+			for v := range values {
+				saved[element][uri][v] = true
+			}
+		}
+	}
+	schemes.Unlock()
+	t.Cleanup(func() {
+		schemes.Lock()
+		schemes.byElement = saved
+		schemes.Unlock()
+	})
+}
+
+func TestValidate(t *testing.T) {
+	const requestTypeScheme = "http://www.niso.org/ncip/v1_0/imp1/schemes/requesttype/requesttype.scm"
+	tests := []struct {
+		msg  interface{}
+		want []Problem
+	}{
+		{
+			&RequestItem{
+				RequestType:      SchemeValue{Scheme: requestTypeScheme, Value: "Hold"},
+				RequestScopeType: SchemeValue{Value: "Item"},
+			},
+			nil,
+		},
+		{
+			// Elements without registered schemes are not validated.
+			LookupUser{
+				InitiationHeader: &InitiationHeader{FromAgencyId: FromAgencyId{AgencyId: SchemeValue{Value: "anything"}}},
+				UserElementType:  []SchemeValue{{Value: "Name Information"}, {Value: " User Id "}},
+			},
+			nil,
+		},
+		{
+			&RequestItem{
+				RequestType:      SchemeValue{Scheme: requestTypeScheme, Value: "Borrow"},
+				RequestScopeType: SchemeValue{Scheme: "http://example.org/scope.scm", Value: "Item"},
+			},
+			[]Problem{
+				ErrUnknownSchemeValue.At("RequestType", "Borrow"),
+				ErrUnknownScheme.At("RequestScopeType", "http://example.org/scope.scm"),
+			},
+		},
+		{
+			&LookupUser{UserElementType: []SchemeValue{{Value: "User Id"}, {Value: "Shoe Size"}}},
+			[]Problem{ErrUnknownSchemeValue.At("UserElementType", "Shoe Size")},
+		},
+		{
+			&LookupItemResponse{ItemOptionalFields: &ItemOptionalFields{CirculationStatus: &SchemeValue{Value: "Eaten By Dog"}}},
+			[]Problem{ErrUnknownSchemeValue.At("CirculationStatus", "Eaten By Dog")},
+		},
+	}
+	for _, test := range tests {
+		err := Validate(test.msg)
+		var got []Problem
+		var pe *ProblemError
+		if errors.As(err, &pe) {
+			got = pe.Problems
+		} else if err != nil {
+			t.Fatalf("Validate(%T) => %v; want *ProblemError", test.msg, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Validate(%+v) => %v; want %v", test.msg, got, test.want)
+		}
+	}
+}
+
+func TestRegisterScheme(t *testing.T) {
+	restoreSchemes(t)
+	for _, s := range []struct {
+		element string
+		scheme  Scheme
+	}{
+		{"", Scheme{URI: "http://example.org/x.scm", Values: []string{"x"}}},
+		{"MediumType", Scheme{Values: []string{"x"}}},
+		{"MediumType", Scheme{URI: "http://example.org/x.scm"}},
+	} {
+		if err := RegisterScheme(s.element, s.scheme); err == nil {
+			t.Errorf("RegisterScheme(%q, %+v) => nil error; want error", s.element, s.scheme)
+		}
+	}
+
+	const local = "http://example.org/schemes/circulationstatus.scm"
+	status := &LookupItemResponse{ItemOptionalFields: &ItemOptionalFields{
+		CirculationStatus: &SchemeValue{Scheme: local, Value: "In Repair"},
+	}}
+	if err := Validate(status); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("Validate with unregistered scheme => %v; want Unknown Scheme", err)
+	}
+	if err := RegisterScheme("CirculationStatus", Scheme{URI: local, Values: []string{"In Repair"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(status); err != nil {
+		t.Errorf("Validate with registered local scheme => %v", err)
+	}
+	status.ItemOptionalFields.CirculationStatus.Scheme = ""
+	if err := Validate(status); err != nil {
+		t.Errorf("Validate with value of local scheme, without scheme => %v", err)
+	}
+}
+
+func TestValidateTestdata(t *testing.T) {
+	restoreSchemes(t)
+	validate := func() (invalid []error) {
+		for _, file := range []string{"testdata/requests.xml", "testdata/responses.xml"} {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			r := bufio.NewReader(f)
+			for b := readMsg(r); b.Len() > 0; b = readMsg(r) {
+				var msg interface{}
+				if file == "testdata/requests.xml" {
+					msg, err = DecodeRequest(&b)
+				} else {
+					msg, err = DecodeResponse(&b)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := Validate(msg); err != nil {
+					invalid = append(invalid, err)
+				}
+			}
+		}
+		return invalid
+	}
+
+	// The request items use a local request type with the URI of the
+	// standard scheme.
+	invalid := validate()
+	if len(invalid) != 2 || !errors.Is(invalid[0], ErrUnknownSchemeValue) {
+		t.Errorf("Validate testdata => %v; want 2 unknown request types", invalid)
+	}
+	err := RegisterScheme("RequestType", Scheme{
+		URI:    "http://www.niso.org/ncip/v1_0/imp1/schemes/requesttype/requesttype.scm",
+		Values: []string{"Recall/Delivery Request"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid := validate(); len(invalid) != 0 {
+		t.Errorf("Validate testdata => %v; want no errors", invalid)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	s := NewServer(nil)
+	s.ErrorLog = log.New(io.Discard, "", 0)
+	s.Validation = true
+	s.HandleFunc(TypeLookupItem, func(req Request) (Response, error) {
+		return &LookupItemResponse{
+			ItemOptionalFields: &ItemOptionalFields{CirculationStatus: &SchemeValue{Value: "Gone Fishing"}},
+		}, nil
+	})
+	s.HandleFunc(TypeLookupUser, func(req Request) (Response, error) {
+		return &LookupUserResponse{}, nil
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	c := NewClient(srv.URL, "a", "b")
+
+	_, err := c.LookupUser(&LookupUser{UserElementType: []SchemeValue{{Value: "Favourite Colour"}}})
+	if !errors.Is(err, ErrUnknownSchemeValue) {
+		t.Errorf("LookupUser with invalid element type => %v; want Unknown Value From Known Scheme", err)
+	}
+	if _, err := c.LookupUser(&LookupUser{UserElementType: []SchemeValue{{Value: "User Id"}}}); err != nil {
+		t.Errorf("LookupUser => %v", err)
+	}
+
+	// The client validates responses only if asked to.
+	if _, err := c.LookupItem(&LookupItem{}); err != nil {
+		t.Errorf("LookupItem => %v", err)
+	}
+	c.Validation = true
+	resp, err := c.LookupItem(&LookupItem{})
+	var pe *ProblemError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrUnknownSchemeValue) || resp == nil || pe.Response != resp {
+		t.Errorf("LookupItem with validation => %v, %v; want response and Unknown Value From Known Scheme", resp, err)
+	}
+}
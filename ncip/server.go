@@ -0,0 +1,177 @@
+package ncip
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as
+// Handlers.
+type HandlerFunc func(Request) Response
+
+// Process calls f(req).
+func (f HandlerFunc) Process(req Request) Response {
+	return f(req)
+}
+
+// Server is an http.Handler serving NCIP requests POSTed to it. Requests are
+// dispatched to the function registered for the request type with
+// HandleFunc, or else to the Handler.
+//
+// Problems processing a request are reported to the initiator as Problem
+// elements:
+//
+//   - "Invalid Message Syntax Error" if the request cannot be decoded.
+//   - "Unsupported Service" if there is nothing to handle the request.
+//   - "Unknown Scheme" or "Unknown Value From Known Scheme" if Validation is
+//     enabled, and the request has values not in the registered schemes.
+//   - "Temporary Processing Failure" if the handler returns no response, a
+//     response of the wrong type, or panics.
+//   - The Problem, or Problems of a *ProblemError, returned by a handler
+//     function as error. Other errors are reported as "Temporary Processing
+//     Failure".
+//
+// If the request type is known, the problem is reported in a response of
+// the corresponding type, otherwise directly in the NCIPMessage element.
+type Server struct {
+	// Handler processes requests of the types without a function registered
+	// with HandleFunc. If nil, they get an "Unsupported Service" problem.
+	Handler Handler
+
+	// If Validation is true, requests are checked with Validate before
+	// they are handled, and the initiator gets the problems found, if any.
+	Validation bool
+
+	// ErrorLog is the logger for handler errors and panics. If nil, the
+	// standard logger of package log is used.
+	ErrorLog *log.Logger
+
+	mu    sync.RWMutex
+	funcs map[requestType]func(Request) (Response, error)
+}
+
+// NewServer returns a new Server dispatching requests to the Handler, which
+// may be nil if all requests to be supported are registered with HandleFunc.
+func NewServer(h Handler) *Server {
+	return &Server{
+		Handler: h,
+		funcs:   make(map[requestType]func(Request) (Response, error)),
+	}
+}
+
+// HandleFunc registers the function handling requests of the given type,
+// replacing any function previously registered for it. If the function
+// returns an error, the initiator gets a response with the Problems of the
+// error instead of the returned response, as returned by ErrorResponse.
+func (s *Server) HandleFunc(t requestType, f func(Request) (Response, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.funcs == nil {
+		s.funcs = make(map[requestType]func(Request) (Response, error))
+	}
+	s.funcs[t] = f
+}
+
+// ServeHTTP decodes the NCIP request in the body of the HTTP request, and
+// writes the NCIP response. Only the POST method is allowed.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var b bytes.Buffer
+	var err error
+	req, problem := decodeRequest(r.Body)
+	switch {
+	case req == nil:
+		err = encode(&b, "Problem", problem)
+	case problem != nil:
+		err = EncodeResponse(&b, ErrorResponse(req, *problem))
+	default:
+		if s.Validation {
+			if verr := Validate(req); verr != nil {
+				err = EncodeResponse(&b, ErrorResponse(req, verr))
+				break
+			}
+		}
+		err = EncodeResponse(&b, s.process(req))
+	}
+	if err != nil {
+		s.logf("ncip: encoding response: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// decodeRequest decodes the request, or returns the problem with it. If
+// the request element cannot be identified, the request is nil.
+func decodeRequest(r io.Reader) (Request, *Problem) {
+	dec := xml.NewDecoder(r)
+	elem, err := messageElement(dec)
+	if err != nil {
+		p := ErrInvalidMessageSyntax.WithDetail(err.Error())
+		return nil, &p
+	}
+	newReq, ok := requestTypes[elem.Name.Local]
+	if !ok {
+		p := ErrUnsupportedService.At(elem.Name.Local, "")
+		return nil, &p
+	}
+	req := newReq()
+	if err := dec.DecodeElement(req, &elem); err != nil {
+		p := ErrInvalidMessageSyntax.WithDetail(err.Error()).At(elem.Name.Local, "")
+		return req, &p
+	}
+	return req, nil
+}
+
+// process dispatches the request, and returns the response, or a response
+// with a problem.
+func (s *Server) process(req Request) (resp Response) {
+	name := requestNames[req.Type()]
+	defer func() {
+		if r := recover(); r != nil {
+			s.logf("ncip: panic handling %s: %v", name, r)
+			resp = ErrorResponse(req, ErrTemporaryProcessingFailure)
+		}
+	}()
+
+	s.mu.RLock()
+	f, ok := s.funcs[req.Type()]
+	s.mu.RUnlock()
+	var err error
+	switch {
+	case ok:
+		resp, err = f(req)
+	case s.Handler != nil:
+		resp = s.Handler.Process(req)
+	default:
+		return ErrorResponse(req, ErrUnsupportedService.At(name, ""))
+	}
+
+	if err == nil && (resp == nil || responseNames[resp.Type()] != name+"Response") {
+		err = fmt.Errorf("handler returned %T", resp)
+	}
+	if err != nil {
+		s.logf("ncip: handling %s: %v", name, err)
+		return ErrorResponse(req, err)
+	}
+	return resp
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,156 @@
+package ncip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testHeader = `<InitiationHeader><FromAgencyId><AgencyId>API9999</AgencyId></FromAgencyId><ToAgencyId><AgencyId>LEHI</AgencyId></ToAgencyId></InitiationHeader>`
+
+func TestServer(t *testing.T) {
+	s := NewServer(HandlerFunc(func(req Request) Response {
+		switch req.(type) {
+		case *CheckInItem:
+			return &CheckInItemResponse{ItemId: &ItemId{ItemIdentifierValue: "15"}}
+		case *AcceptItem:
+			return &CheckInItemResponse{} // wrong type
+		case *CancelRequestItem:
+			panic("oops")
+		}
+		return nil
+	}))
+	s.ErrorLog = log.New(io.Discard, "", 0)
+	s.HandleFunc(TypeLookupUser, func(req Request) (Response, error) {
+		switch id := req.(*LookupUser).UserId.UserIdentifierValue; id {
+		case "p1":
+		case "p3":
+			return nil, fmt.Errorf("lookup: %w", ErrUnknownUser.At("UserIdentifierValue", id))
+		default:
+			return nil, errors.New("no such user")
+		}
+		return LookupUserResponse{UserId: &UserId{UserIdentifierValue: "p1"}}, nil
+	})
+	s.HandleFunc(TypeCreateUser, func(req Request) (Response, error) {
+		return nil, nil
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	tests := []struct {
+		req  string
+		want []string
+	}{
+		{
+			"<NCIPMessage><LookupUser>" + testHeader + "<UserId><UserIdentifierValue>p1</UserIdentifierValue></UserId></LookupUser></NCIPMessage>",
+			[]string{"<LookupUserResponse>\n    <UserId>\n      <UserIdentifierValue>p1</UserIdentifierValue>"},
+		},
+		{
+			"<NCIPMessage><CheckInItem>" + testHeader + "<ItemId><ItemIdentifierValue>15</ItemIdentifierValue></ItemId></CheckInItem></NCIPMessage>",
+			[]string{"<CheckInItemResponse>\n    <ItemId>"},
+		},
+		{
+			// Handler error.
+			"<NCIPMessage><LookupUser>" + testHeader + "<UserId><UserIdentifierValue>p2</UserIdentifierValue></UserId></LookupUser></NCIPMessage>",
+			[]string{
+				"<LookupUserResponse>\n    <ResponseHeader>\n      <FromAgencyId>\n        <AgencyId>LEHI</AgencyId>",
+				">Temporary Processing Failure</ProblemType>",
+				"<ProblemDetail>no such user</ProblemDetail>",
+			},
+		},
+		{
+			// Handler error with a Problem.
+			"<NCIPMessage><LookupUser>" + testHeader + "<UserId><UserIdentifierValue>p3</UserIdentifierValue></UserId></LookupUser></NCIPMessage>",
+			[]string{
+				`<ProblemType Scheme="` + SchemeGeneralProcessingError + `">Unknown User</ProblemType>`,
+				"<ProblemElement>UserIdentifierValue</ProblemElement>\n      <ProblemValue>p3</ProblemValue>",
+			},
+		},
+		{
+			// Handler function returns no response.
+			"<NCIPMessage><CreateUser>" + testHeader + "</CreateUser></NCIPMessage>",
+			[]string{"<CreateUserResponse>", ">Temporary Processing Failure</ProblemType>"},
+		},
+		{
+			// Handler returns response of the wrong type.
+			"<NCIPMessage><AcceptItem>" + testHeader + "</AcceptItem></NCIPMessage>",
+			[]string{"<AcceptItemResponse>", ">Temporary Processing Failure</ProblemType>"},
+		},
+		{
+			// Handler panics.
+			"<NCIPMessage><CancelRequestItem>" + testHeader + "</CancelRequestItem></NCIPMessage>",
+			[]string{"<CancelRequestItemResponse>", ">Temporary Processing Failure</ProblemType>"},
+		},
+		{
+			// Handler returns nil.
+			"<NCIPMessage><RenewItem>" + testHeader + "</RenewItem></NCIPMessage>",
+			[]string{"<RenewItemResponse>", ">Temporary Processing Failure</ProblemType>"},
+		},
+		{
+			"<NCIPMessage><FlyAway/></NCIPMessage>",
+			[]string{
+				"\n  <Problem>\n",
+				">Unsupported Service</ProblemType>",
+				"<ProblemElement>FlyAway</ProblemElement>",
+			},
+		},
+		{
+			"<NCIPMessage><LookupUser><UserId>",
+			[]string{"<LookupUserResponse>", ">Invalid Message Syntax Error</ProblemType>"},
+		},
+		{
+			"<NCIPMessage",
+			[]string{"\n  <Problem>\n", ">Invalid Message Syntax Error</ProblemType>"},
+		},
+	}
+
+	for _, test := range tests {
+		resp, err := http.Post(srv.URL, "application/xml", strings.NewReader(test.req))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("POST %s => %s; want 200 OK", test.req, resp.Status)
+		}
+		if !bytes.HasPrefix(body, []byte(`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+envelope)) {
+			t.Errorf("POST %s => response without NCIPMessage envelope:\n%s", test.req, body)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("POST %s =>\n%s\nwant response containing %q", test.req, body, want)
+			}
+		}
+	}
+
+	// A request without a handler is not supported.
+	s.Handler = nil
+	resp, err := http.Post(srv.URL, "application/xml", strings.NewReader("<NCIPMessage><CheckInItem/></NCIPMessage>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want := ">Unsupported Service</ProblemType>"; !strings.Contains(string(body), want) || !strings.Contains(string(body), "<CheckInItemResponse>") {
+		t.Errorf("CheckInItem without handler =>\n%s\nwant CheckInItemResponse containing %q", body, want)
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET => %s; want 405 Method Not Allowed", resp.Status)
+	}
+}
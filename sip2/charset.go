@@ -0,0 +1,108 @@
+package sip2
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset is the character encoding of SIP messages on the wire. Messages
+// are always UTF-8 strings in Go; they are transcoded when encoded and
+// decoded with a Charset other than UTF8.
+type Charset int
+
+// Supported character encodings.
+const (
+	UTF8   Charset = iota // UTF-8, the default
+	ASCII                 // 7-bit US-ASCII
+	Latin1                // ISO-8859-1
+)
+
+func (c Charset) String() string {
+	switch c {
+	case UTF8:
+		return "UTF-8"
+	case ASCII:
+		return "ASCII"
+	case Latin1:
+		return "ISO-8859-1"
+	}
+	return fmt.Sprintf("Charset(%d)", int(c))
+}
+
+// ParseCharset returns the Charset with the given name, ex: "utf-8",
+// "ascii" or "iso-8859-1". Names are case insensitive.
+func ParseCharset(name string) (Charset, error) {
+	switch strings.ToLower(name) {
+	case "utf-8", "utf8":
+		return UTF8, nil
+	case "ascii", "us-ascii":
+		return ASCII, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		return Latin1, nil
+	}
+	return UTF8, fmt.Errorf("sip2: unsupported charset: %q", name)
+}
+
+// max returns the highest code point which can be represented in the Charset.
+func (c Charset) max() rune {
+	switch c {
+	case ASCII:
+		return utf8.RuneSelf - 1
+	case Latin1:
+		return 0xFF
+	}
+	return utf8.MaxRune
+}
+
+// EncodingError reports characters which cannot be represented in a Charset.
+type EncodingError struct {
+	Charset Charset
+	Chars   []rune // the unencodable characters, in order of appearance
+}
+
+func (e *EncodingError) Error() string {
+	return fmt.Sprintf("sip2: cannot encode %q in %v", string(e.Chars), e.Charset)
+}
+
+// encode transcodes a UTF-8 string to the Charset. Characters which cannot be
+// represented are replaced with '?', and reported in an *EncodingError.
+func (c Charset) encode(s string) ([]byte, error) {
+	if c == UTF8 {
+		return []byte(s), nil
+	}
+	var bad []rune
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > c.max() {
+			bad = append(bad, r)
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+	if bad != nil {
+		return b, &EncodingError{Charset: c, Chars: bad}
+	}
+	return b, nil
+}
+
+// decode transcodes bytes in the Charset to UTF-8. Bytes which are not
+// valid in the Charset are reported as an error.
+func (c Charset) decode(b []byte) ([]byte, error) {
+	switch c {
+	case UTF8:
+		return b, nil
+	case ASCII:
+		for i, x := range b {
+			if x >= utf8.RuneSelf {
+				return nil, fmt.Errorf("Decode: invalid ASCII byte %#x at position %d", x, i)
+			}
+		}
+		return b, nil
+	}
+	res := make([]byte, 0, len(b)+len(b)/8)
+	for _, x := range b {
+		res = utf8.AppendRune(res, rune(x))
+	}
+	return res, nil
+}
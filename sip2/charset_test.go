@@ -0,0 +1,140 @@
+package sip2
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestCharset(t *testing.T) {
+	msg := NewMessage(MsgRespLogin).AddField(Field{Type: FieldOK, Value: "1"})
+	patron := NewMessage(MsgReqPatronEnable).AddField(
+		Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+		Field{Type: FieldInstitutionID, Value: "Bærum"},
+		Field{Type: FieldPatronIdentifier, Value: "Øyvind Åsen"},
+	)
+
+	tests := []struct {
+		msg     Message
+		charset Charset
+		want    string
+		bad     []rune
+	}{
+		{msg, ASCII, "941\r", nil},
+		{patron, UTF8, "2520160822    153450AOBærum|AAØyvind Åsen|\r", nil},
+		{patron, Latin1, "2520160822    153450AOB\xe6rum|AA\xd8yvind \xc5sen|\r", nil},
+		{patron, ASCII, "2520160822    153450AOB?rum|AA?yvind ?sen|\r", []rune("æØÅ")},
+		{
+			NewMessage(MsgReqPatronEnable).AddField(
+				Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+				Field{Type: FieldInstitutionID, Value: "Łódź"},
+				Field{Type: FieldPatronIdentifier, Value: "ó"},
+			),
+			Latin1, "2520160822    153450AO?\xf3d?|AA\xf3|\r", []rune("Łź"),
+		},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		err := test.msg.EncodeCharset(&b, test.charset)
+		if b.String() != test.want {
+			t.Errorf("EncodeCharset(%v) => %q; want %q", test.charset, b.String(), test.want)
+		}
+		var encErr *EncodingError
+		if test.bad == nil && err != nil {
+			t.Errorf("EncodeCharset(%v) => %v", test.charset, err)
+		} else if test.bad != nil && (!errors.As(err, &encErr) || !reflect.DeepEqual(encErr.Chars, test.bad)) {
+			t.Errorf("EncodeCharset(%v) => %v; want EncodingError for %q", test.charset, err, string(test.bad))
+		}
+		if test.bad != nil {
+			continue
+		}
+
+		got, err := DecodeCharset(b.Bytes(), test.charset)
+		if err != nil {
+			t.Fatalf("DecodeCharset(%q, %v) => %v", b.String(), test.charset, err)
+		}
+		if got.String() != test.msg.String() {
+			t.Errorf("DecodeCharset(%q, %v) => %q; want %q", b.String(), test.charset, got.String(), test.msg.String())
+		}
+	}
+
+	if _, err := DecodeCharset([]byte("2520160822    153450AOB\xe6rum|AAx|"), ASCII); err == nil {
+		t.Error("DecodeCharset with non-ASCII byte => nil error; want error")
+	}
+}
+
+func TestCharsetChecksum(t *testing.T) {
+	// The checksum is computed over the encoded bytes.
+	msg := NewMessage(MsgRespPatronEnable).AddField(
+		Field{Type: FieldPatronStatus, Value: "              "},
+		Field{Type: FieldLanguage, Value: "012"},
+		Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+		Field{Type: FieldInstitutionID, Value: "x"},
+		Field{Type: FieldPatronIdentifier, Value: "1"},
+		Field{Type: FieldPersonalName, Value: "Ærlig"},
+		Field{Type: FieldChecksum},
+	)
+	var b bytes.Buffer
+	if err := msg.EncodeCharset(&b, Latin1); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeCharset(b.Bytes(), Latin1)
+	if err != nil {
+		t.Fatalf("DecodeCharset(%q) => %v", b.String(), err)
+	}
+	if name := got.Field(FieldPersonalName); name != "Ærlig" {
+		t.Errorf("personal name = %q; want %q", name, "Ærlig")
+	}
+}
+
+func TestServerCharset(t *testing.T) {
+	h := HandlerFunc(func(req Message) Message {
+		return NewMessage(MsgRespPatronEnable).AddField(
+			Field{Type: FieldPatronStatus, Value: "              "},
+			Field{Type: FieldLanguage, Value: "012"},
+			Field{Type: FieldTransactionDate, Value: req.Field(FieldTransactionDate)},
+			Field{Type: FieldInstitutionID, Value: req.Field(FieldInstitutionID)},
+			Field{Type: FieldPatronIdentifier, Value: req.Field(FieldPatronIdentifier)},
+			Field{Type: FieldPersonalName, Value: "Åse Ødegård"},
+		)
+	})
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ClientCharsets = map[string]Charset{"127.0.0.1": Latin1}
+	go s.Run()
+	defer s.Close()
+
+	c, err := net.Dial("tcp", localAddr(s.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.Write([]byte("2520160822    153450AOB\xe6rum|AA1|\r")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := bufio.NewReader(c).ReadBytes('\r')
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "26              01220160822    153450AOB\xe6rum|AA1|AE\xc5se \xd8deg\xe5rd|\r"
+	if string(b) != want {
+		t.Errorf("got %q; want %q", b, want)
+	}
+}
+
+func TestClientCharset(t *testing.T) {
+	c := NewClient("127.0.0.1:0", testMF)
+	c.Charset = ASCII
+	_, err := c.PatronInformation("Ødegård", "")
+	var encErr *EncodingError
+	if !errors.As(err, &encErr) {
+		t.Errorf("PatronInformation with unencodable patron => %v; want *EncodingError", err)
+	}
+}
@@ -0,0 +1,373 @@
+package sip2
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// responseTypes maps request message types to the expected response type.
+var responseTypes = map[msgType]msgType{
+	MsgReqPatronStatus:      MsgRespPatronStatus,
+	MsgReqCheckout:          MsgRespCheckout,
+	MsgReqCheckin:           MsgRespCheckin,
+	MsgReqBlockPatron:       MsgRespPatronStatus,
+	MsgReqStatus:            MsgRespStatus,
+	MsgReqLogin:             MsgRespLogin,
+	MsgReqPatronInformation: MsgRespPatronInformation,
+	MsgReqEndPatronSession:  MsgRespEndPatronSession,
+	MsgReqFeePaid:           MsgRespFeePaid,
+	MsgReqItemInformation:   MsgRespItemInformation,
+	MsgReqItemStatusUpdate:  MsgRespItemStatusUpdate,
+	MsgReqPatronEnable:      MsgRespPatronEnable,
+	MsgReqHold:              MsgRespHold,
+	MsgReqRenew:             MsgRespRenew,
+	MsgReqRenewAll:          MsgRespRenewAll,
+}
+
+// ErrLoginFailed is returned when the ACS responds to a login request with
+// a not OK status.
+var ErrLoginFailed = errors.New("sip2: login failed")
+
+// Client is a SIP2 client, which sends requests from a self-service
+// terminal (SC) to an ACS over a TCP connection. The connection is
+// established lazily, and reestablished if it fails, including a new login
+// if Login has been called.
+//
+// SIP2 allows only one outstanding request per connection, so a Client
+// serializes all requests. It is safe for concurrent use.
+type Client struct {
+	addr string
+	mf   MessageFactory
+
+	// Timeout is the maximum duration of a request-response roundtrip,
+	// including any connection attempt. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// Retries is the number of times to reconnect and resend a request
+	// if the connection fails. Defaults to 1.
+	Retries int
+
+	// TLSConfig is used to establish a TLS connection to the ACS. If nil,
+	// a plain TCP connection is used.
+	TLSConfig *tls.Config
+
+	// When ErrorDetection is true, requests are sent with a sequence number
+	// and checksum. Responses with a bad checksum are requested resent, and
+	// requests are resent when asked to by the ACS.
+	ErrorDetection bool
+
+	// Charset is the character encoding of messages to and from the ACS.
+	// Defaults to UTF8. Requests with characters which cannot be represented
+	// in the Charset are not sent; Send returns an *EncodingError instead.
+	Charset Charset
+
+	mu    sync.Mutex
+	conn  net.Conn
+	r     *bufio.Reader
+	login *Message // sent on each new connection, if set
+	seq   int      // last sequence number used, 0-9
+}
+
+// maxResends is the maximum number of times a message is resent on the same
+// connection in error detection mode, before giving up.
+const maxResends = 3
+
+// NewClient returns a new Client for the ACS at the given address.
+// Messages are created with the given MessageFactory, which should hold the
+// defaults for the SC, typically the institution ID and terminal password.
+// NewClient does not connect; that is done on the first request.
+func NewClient(addr string, mf MessageFactory) *Client {
+	return &Client{
+		addr:    addr,
+		mf:      mf,
+		Timeout: 30 * time.Second,
+		Retries: 1,
+	}
+}
+
+// Dial is like NewClient, but establishes the connection immediately,
+// returning an error if it fails.
+func Dial(addr string, mf MessageFactory) (*Client, error) {
+	c := NewClient(addr, mf)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.connect(time.Now().Add(c.Timeout)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect(deadline time.Time) error {
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Deadline: deadline}
+	if c.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, c.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.r = bufio.NewReader(conn)
+
+	if c.login != nil {
+		resp, err := c.roundtrip(*c.login, deadline)
+		if err != nil {
+			c.disconnect()
+			return err
+		}
+		if resp.Field(FieldOK) != "1" {
+			c.disconnect()
+			return ErrLoginFailed
+		}
+	}
+	return nil
+}
+
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.r = nil
+	}
+}
+
+// roundtrip sends a request and reads a response on the current connection.
+func (c *Client) roundtrip(req Message, deadline time.Time) (Message, error) {
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return Message{}, err
+	}
+	if c.ErrorDetection {
+		c.seq = (c.seq + 1) % 10
+		req.AddField(
+			Field{Type: FieldSequenceNumber, Value: strconv.Itoa(c.seq)},
+			Field{Type: FieldChecksum},
+		)
+	}
+	encoded, err := req.encode(c.Charset)
+	if err != nil {
+		return Message{}, err
+	}
+	if _, err := c.conn.Write(encoded); err != nil {
+		return Message{}, err
+	}
+
+	var resp Message
+	for resends := 0; ; resends++ {
+		b, err := c.r.ReadBytes('\r')
+		if err != nil {
+			return Message{}, err
+		}
+		resp, err = DecodeCharset(b, c.Charset)
+		if c.ErrorDetection && resends < maxResends {
+			if err == ErrChecksum {
+				// Ask the ACS to resend the garbled response.
+				if err := NewMessage(MsgReqResend).AddField(Field{Type: FieldChecksum}).Encode(c.conn); err != nil {
+					return Message{}, err
+				}
+				continue
+			}
+			if err == nil && resp.typ == MsgRespResend {
+				if _, err := c.conn.Write(encoded); err != nil {
+					return Message{}, err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			return Message{}, err
+		}
+		break
+	}
+
+	if want, ok := responseTypes[req.typ]; ok && resp.typ != want {
+		return resp, fmt.Errorf("sip2: unexpected response to %v: got %v; want %v", req.typ, resp.typ, want)
+	}
+	if seq, ok := resp.FieldOK(FieldSequenceNumber); c.ErrorDetection && ok && seq != req.Field(FieldSequenceNumber) {
+		return resp, fmt.Errorf("sip2: response sequence number %s does not match request sequence number %s",
+			seq, req.Field(FieldSequenceNumber))
+	}
+	return resp, nil
+}
+
+// Send sends a request to the ACS and returns the response. The response
+// is guaranteed to be of the type corresponding to the request type.
+//
+// If the connection fails, Send will reconnect and resend the request
+// up to Retries times.
+func (c *Client) Send(req Message) (Message, error) {
+	if _, ok := responseTypes[req.typ]; !ok {
+		return Message{}, fmt.Errorf("sip2: cannot send %v", req.typ)
+	}
+	if _, err := req.encode(c.Charset); err != nil {
+		return Message{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deadline := time.Now().Add(c.Timeout)
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if c.conn == nil {
+			if err = c.connect(deadline); err != nil {
+				if err == ErrLoginFailed {
+					return Message{}, err
+				}
+				continue
+			}
+		}
+
+		var resp Message
+		resp, err = c.roundtrip(req, deadline)
+		if err == nil {
+			return resp, nil
+		}
+		if resp.typ != MsgUnknown {
+			// The ACS responded, but not with the expected message type.
+			// The connection is fine, so there is no point in retrying.
+			return resp, err
+		}
+		c.disconnect()
+	}
+
+	return Message{}, err
+}
+
+// Close closes the connection to the ACS, if any.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.r = nil
+	return err
+}
+
+// newMessage returns a new Message of the given type from the Client's MessageFactory,
+// with the given fields added, and any missing required fixed-length fields set
+// to their default values.
+func (c *Client) newMessage(t msgType, fs ...Field) Message {
+	msg := c.mf.NewMessage(t).AddField(fs...)
+	for _, f := range msgDefinitions[t].RequiredFixed {
+		if msg.hasField(f) {
+			continue
+		}
+		if def, ok := fixedFieldDefaults[f]; ok {
+			msg.AddField(Field{Type: f, Value: def})
+		}
+	}
+	return msg
+}
+
+// fixedFieldDefaults are the values used by Client for required fixed-length
+// fields when not given by the MessageFactory.
+var fixedFieldDefaults = map[fieldType]string{
+	FieldLanguage:         "000", // unknown
+	FieldMaxPrintWidth:    "040",
+	FieldNoBlock:          "N",
+	FieldProtocolVersion:  "2.00",
+	FieldPWDAlgorithm:     "0", // not encrypted
+	FieldRenewalPolicy:    "N",
+	FieldStatusCode:       "0", // SC OK
+	FieldSummary:          "          ",
+	FieldThirdPartyAllowd: "N",
+	FieldUIDAlgorithm:     "0", // not encrypted
+}
+
+// Login sends a Login request with the given credentials. The credentials are
+// kept by the Client, and used to log in again on reconnect. It returns
+// ErrLoginFailed if the ACS does not accept the login.
+func (c *Client) Login(user, password, location string) error {
+	req := c.newMessage(MsgReqLogin,
+		Field{Type: FieldLoginUserID, Value: user},
+		Field{Type: FieldLoginPassword, Value: password},
+	)
+	if location != "" {
+		req.AddField(Field{Type: FieldLocationCode, Value: location})
+	}
+
+	resp, err := c.Send(req)
+	if err != nil {
+		return err
+	}
+	if resp.Field(FieldOK) != "1" {
+		return ErrLoginFailed
+	}
+
+	c.setLogin(&req)
+	return nil
+}
+
+// setLogin sets the Login request sent, and checked, on each new connection,
+// or none if nil.
+func (c *Client) setLogin(req *Message) {
+	c.mu.Lock()
+	c.login = req
+	c.mu.Unlock()
+}
+
+// Status sends a SC Status request, and returns the ACS Status response.
+func (c *Client) Status() (Message, error) {
+	return c.Send(c.newMessage(MsgReqStatus))
+}
+
+// Checkout sends a Checkout request for the given patron and item.
+func (c *Client) Checkout(patron, item string) (Message, error) {
+	return c.Send(c.newMessage(MsgReqCheckout,
+		Field{Type: FieldPatronIdentifier, Value: patron},
+		Field{Type: FieldItemIdentifier, Value: item},
+	))
+}
+
+// Checkin sends a Checkin request for the given item, returned at the given location.
+func (c *Client) Checkin(item, location string) (Message, error) {
+	return c.Send(c.newMessage(MsgReqCheckin,
+		Field{Type: FieldItemIdentifier, Value: item},
+		Field{Type: FieldCurrentLocation, Value: location},
+	))
+}
+
+// PatronInformation sends a Patron Information request for the given patron.
+// The password is optional, and only sent if not empty.
+func (c *Client) PatronInformation(patron, password string) (Message, error) {
+	req := c.newMessage(MsgReqPatronInformation,
+		Field{Type: FieldPatronIdentifier, Value: patron},
+	)
+	if password != "" {
+		req.AddField(Field{Type: FieldPatronPassword, Value: password})
+	}
+	return c.Send(req)
+}
+
+// Renew sends a Renew request for the given patron and item.
+func (c *Client) Renew(patron, item string) (Message, error) {
+	return c.Send(c.newMessage(MsgReqRenew,
+		Field{Type: FieldPatronIdentifier, Value: patron},
+		Field{Type: FieldItemIdentifier, Value: item},
+	))
+}
+
+// FeePaid sends a Fee Paid request, informing the ACS that the patron has paid
+// the given amount. The fee and payment types are 2-digit codes as defined by
+// the protocol, and currency is a 3-letter ISO 4217 code.
+func (c *Client) FeePaid(patron, feeType, paymentType, currency, amount string) (Message, error) {
+	return c.Send(c.newMessage(MsgReqFeePaid,
+		Field{Type: FieldPatronIdentifier, Value: patron},
+		Field{Type: FieldFeeType, Value: feeType},
+		Field{Type: FieldPaymentType, Value: paymentType},
+		Field{Type: FieldCurrenyType, Value: currency},
+		Field{Type: FieldFeeAmount, Value: amount},
+	))
+}
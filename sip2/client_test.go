@@ -0,0 +1,173 @@
+package sip2
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+// loginHandler wraps a testHandler, and handles login and status requests.
+type loginHandler struct {
+	*testHandler
+	user, password string
+}
+
+func (h loginHandler) Handle(req Message) Message {
+	switch req.Type() {
+	case MsgReqLogin:
+		ok := "0"
+		if req.Field(FieldLoginUserID) == h.user && req.Field(FieldLoginPassword) == h.password {
+			ok = "1"
+		}
+		return testMF.NewMessage(MsgRespLogin).AddField(Field{Type: FieldOK, Value: ok})
+	case MsgReqStatus:
+		return testMF.NewMessage(MsgRespStatus).AddField(
+			Field{Type: FieldOnLineStatus, Value: "Y"},
+			Field{Type: FieldCheckinOK, Value: "Y"},
+			Field{Type: FieldCheckoutOK, Value: "Y"},
+			Field{Type: FieldRenewalPolicy, Value: "Y"},
+			Field{Type: FieldStatusUpdateOK, Value: "N"},
+			Field{Type: FieldOffLineOK, Value: "N"},
+			Field{Type: FieldTimeoutPeriod, Value: "030"},
+			Field{Type: FieldRetriesAllowed, Value: "003"},
+			Field{Type: FieldDateTimeSync, Value: time.Now().Format(DateLayout)},
+			Field{Type: FieldProtocolVersion, Value: "2.00"},
+			Field{Type: FieldSupportedMessages, Value: "YYYYYYYYYYYYYYYY"},
+		)
+	}
+	return h.testHandler.Handle(req)
+}
+
+func TestClient(t *testing.T) {
+	books := []string{"Sult - Knut Hamsun", "Hvite Netter - Fjodor Dostovjevski"}
+	h := loginHandler{newTestHandler(books, []string{"Ole Jensen"}), "kiosk", "secret"}
+
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	c, err := Dial(localAddr(s.ln.Addr().String()), testMF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Login("kiosk", "wrong", ""); err != ErrLoginFailed {
+		t.Errorf("Login with wrong password got %v; want %v", err, ErrLoginFailed)
+	}
+	if err := c.Login("kiosk", "secret", "here"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	resp, err := c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldOnLineStatus) != "Y" {
+		t.Errorf("Status: %v got %q; want %q", FieldOnLineStatus, resp.Field(FieldOnLineStatus), "Y")
+	}
+
+	resp, err = c.Checkout("0", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldOK) != "1" || resp.Field(FieldTitleIdentifier) != books[1] {
+		t.Errorf("Checkout got %v", resp)
+	}
+
+	resp, err = c.Checkin("1", "here")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldOK) != "1" {
+		t.Errorf("Checkin got %v", resp)
+	}
+
+	// testHandler responds to renew requests with a message of the wrong type
+	if _, err := c.Renew("0", "1"); err == nil {
+		t.Error("Renew with unexpected response type got nil; want error")
+	}
+}
+
+// TestClientReconnect verifies that the client reconnects, logs in again
+// and resends the request when the ACS closes the connection.
+func TestClientReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	h := loginHandler{newTestHandler([]string{"Sult - Knut Hamsun"}, nil), "kiosk", "secret"}
+	logins := make(chan bool, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// Answer two requests (login + one more), then hang up.
+			r := bufio.NewReader(conn)
+			for i := 0; i < 2; i++ {
+				b, err := r.ReadBytes('\r')
+				if err != nil {
+					break
+				}
+				req, err := Decode(b)
+				if err != nil {
+					break
+				}
+				if req.Type() == MsgReqLogin {
+					logins <- true
+				}
+				h.Handle(req).Encode(conn)
+			}
+			conn.Close()
+		}
+	}()
+
+	c := NewClient(ln.Addr().String(), testMF)
+	c.Timeout = 2 * time.Second
+	defer c.Close()
+
+	if err := c.Login("kiosk", "secret", ""); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Status(); err != nil {
+			t.Fatalf("Status %d failed: %v", i, err)
+		}
+	}
+	if len(logins) < 2 {
+		t.Errorf("got %d logins; want at least 2", len(logins))
+	}
+}
+
+func TestClientErrorDetection(t *testing.T) {
+	h := loginHandler{newTestHandler([]string{"Sult - Knut Hamsun"}, nil), "kiosk", "secret"}
+
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	c := NewClient(localAddr(s.ln.Addr().String()), testMF)
+	c.ErrorDetection = true
+	defer c.Close()
+
+	for i := 0; i < 12; i++ {
+		resp, err := c.Status()
+		if err != nil {
+			t.Fatalf("Status %d failed: %v", i, err)
+		}
+		if _, ok := resp.FieldOK(FieldChecksum); !ok {
+			t.Fatalf("Status %d: response without checksum", i)
+		}
+	}
+}
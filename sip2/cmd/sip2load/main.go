@@ -0,0 +1,187 @@
+// Command sip2load simulates many self-service terminals against a SIP2
+// server, to test its performance and its conformance to the protocol.
+//
+// Usage:
+//
+//	sip2load -addr acs.example.org:6001 -terminals 50 -iterations 20 [script]
+//
+// Each terminal runs the script on its own connection, the given number of
+// iterations, or repeatedly until -duration has passed. The script has one
+// step per line; empty lines and lines starting with # are ignored:
+//
+//	login USER PASSWORD [LOCATION]
+//	status
+//	patron PATRON [PASSWORD]
+//	checkout PATRON ITEM
+//	checkin ITEM [LOCATION]
+//	renew PATRON ITEM
+//	item ITEM
+//	sleep DURATION
+//
+// In the arguments, {t} is replaced by the number of the terminal, {i} by
+// the number of the iteration, and {user} and {pass} by the values of the
+// -user and -pass flags. Without a script, each terminal logs in, and checks
+// out and checks in an item:
+//
+//	login {user} {pass}
+//	status
+//	patron patron{t}
+//	checkout patron{t} item{t}-{i}
+//	checkin item{t}-{i}
+//
+// When done, sip2load reports the latencies of each operation, with
+// histograms, and a conformance report listing the responses which lack
+// required fields or have invalid field values. It exits with status 1 if
+// any request failed or any response did not conform.
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+// config is the configuration of a load test.
+type config struct {
+	addr       string
+	terminals  int
+	iterations int
+	duration   time.Duration // if > 0, run until it has passed instead of iterations
+	rate       float64       // maximum requests per second of all terminals; 0 is unlimited
+	timeout    time.Duration
+	tlsConfig  *tls.Config
+	user, pass string
+	terminal   terminal
+}
+
+// terminal holds the fields sent by a terminal in each request.
+type terminal struct {
+	institution string
+	password    string
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sip2load: ")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sip2load [flags] [script]\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+
+	var cfg config
+	var useTLS bool
+	flag.StringVar(&cfg.addr, "addr", "localhost:6001", "address of the SIP2 server")
+	flag.IntVar(&cfg.terminals, "terminals", 10, "number of concurrent terminals")
+	flag.IntVar(&cfg.iterations, "iterations", 1, "number of times each terminal runs the script")
+	flag.DurationVar(&cfg.duration, "duration", 0, "run the script repeatedly for this long, instead of -iterations times")
+	flag.Float64Var(&cfg.rate, "rate", 0, "maximum number of requests per second, of all terminals (0 is unlimited)")
+	flag.DurationVar(&cfg.timeout, "timeout", 10*time.Second, "timeout of each request")
+	flag.BoolVar(&useTLS, "tls", false, "connect over TLS")
+	flag.StringVar(&cfg.user, "user", "", "value of {user} in the script")
+	flag.StringVar(&cfg.pass, "pass", "", "value of {pass} in the script")
+	flag.StringVar(&cfg.terminal.institution, "institution", "", "institution ID sent in requests")
+	flag.StringVar(&cfg.terminal.password, "terminal-password", "", "terminal password sent in requests")
+	flag.Parse()
+
+	if useTLS {
+		cfg.tlsConfig = &tls.Config{}
+	}
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	script := io.Reader(strings.NewReader(defaultScript))
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		script = f
+	}
+	steps, err := parseScript(script)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	st := run(cfg, steps)
+	st.report(os.Stdout, time.Since(start))
+	if st.failed() {
+		os.Exit(1)
+	}
+}
+
+// run runs the script on the configured number of terminals, and returns
+// the collected statistics.
+func run(cfg config, steps []step) *stats {
+	st := newStats()
+
+	// Limit the rate of requests with a ticker shared by all terminals.
+	var tick <-chan time.Time
+	if cfg.rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+		defer t.Stop()
+		tick = t.C
+	}
+
+	var deadline time.Time
+	if cfg.duration > 0 {
+		deadline = time.Now().Add(cfg.duration)
+	}
+
+	var wg sync.WaitGroup
+	for n := 1; n <= cfg.terminals; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			c := sip2.NewClient(cfg.addr, sip2.MessageFactory{})
+			c.Timeout = cfg.timeout
+			c.Retries = 0
+			c.TLSConfig = cfg.tlsConfig
+			defer c.Close()
+
+			for i := 1; ; i++ {
+				if deadline.IsZero() && i > cfg.iterations {
+					return
+				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return
+				}
+				vars := strings.NewReplacer(
+					"{t}", strconv.Itoa(n),
+					"{i}", strconv.Itoa(i),
+					"{user}", cfg.user,
+					"{pass}", cfg.pass,
+				)
+				for _, s := range steps {
+					if s.op == "sleep" {
+						d, _ := time.ParseDuration(s.args[0])
+						time.Sleep(d)
+						continue
+					}
+					req, _ := s.request(cfg.terminal, vars)
+					if tick != nil {
+						<-tick
+					}
+					t0 := time.Now()
+					resp, err := c.Send(req)
+					st.add(s.op, time.Since(t0), resp, err)
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+	return st
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+func TestParseScript(t *testing.T) {
+	steps, err := parseScript(strings.NewReader(defaultScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 5 || steps[0].op != "login" || steps[4].op != "checkin" {
+		t.Errorf("parseScript(defaultScript) => %v", steps)
+	}
+
+	for _, bad := range []string{
+		"",
+		"# only a comment\n",
+		"checkout p1\n",
+		"fly away\n",
+		"sleep forever\n",
+	} {
+		if _, err := parseScript(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseScript(%q) => nil error; want error", bad)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	rt := sip2.NewRouter()
+	rt.RouteFunc(sip2.MsgReqCheckout, func(req sip2.Message) sip2.Message {
+		// An invalid value of a required field.
+		return sip2.CheckoutResponse{
+			MagneticMedia:    "X",
+			Desensitize:      sip2.No,
+			TransactionDate:  time.Now(),
+			PatronIdentifier: req.Field(sip2.FieldPatronIdentifier),
+		}.Message()
+	})
+	s, err := sip2.NewServer(rt, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	steps, err := parseScript(strings.NewReader(defaultScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		addr:       s.Addr().String(),
+		terminals:  3,
+		iterations: 2,
+		timeout:    time.Second,
+		user:       "u",
+		pass:       "p",
+	}
+	st := run(cfg, steps)
+
+	if n := len(st.ops["login"].latencies); n != 6 {
+		t.Errorf("got %d login responses; want 6", n)
+	}
+	if st.ops["checkout"].invalid != 6 {
+		t.Errorf("got %d invalid checkout responses; want 6", st.ops["checkout"].invalid)
+	}
+	if st.ops["status"].invalid != 0 || st.ops["checkin"].invalid != 0 {
+		t.Errorf("got invalid status or checkin responses: %v", st.violations)
+	}
+	if !st.failed() {
+		t.Error("failed() => false; want true")
+	}
+
+	var out bytes.Buffer
+	st.report(&out, time.Second)
+	for _, want := range []string{
+		"30 requests in 1s",
+		"checkout latency:",
+		"MsgRespCheckout:",
+		`6  fixed-length field FieldMagneticMedia with value "X" does not match`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+// defaultScript is run by each terminal if no script is given.
+const defaultScript = `# Log in, then check out and check in an item.
+login {user} {pass}
+status
+patron patron{t}
+checkout patron{t} item{t}-{i}
+checkin item{t}-{i}
+`
+
+// step is a step in a script: a request to send, or a pause.
+type step struct {
+	op   string
+	args []string
+	line int
+}
+
+// ops lists the operations of a script, with their minimum and maximum
+// number of arguments.
+var ops = map[string][2]int{
+	"login":    {2, 3}, // user password [location]
+	"status":   {0, 0},
+	"patron":   {1, 2}, // patron [password]
+	"checkout": {2, 2}, // patron item
+	"checkin":  {1, 2}, // item [location]
+	"renew":    {2, 2}, // patron item
+	"item":     {1, 1}, // item
+	"sleep":    {1, 1}, // duration
+}
+
+// parseScript parses a script of one step per line. Empty lines and lines
+// starting with # are ignored.
+func parseScript(r io.Reader) ([]step, error) {
+	var steps []step
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		s := step{op: fields[0], args: fields[1:], line: n}
+		nargs, ok := ops[s.op]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown operation %q", n, s.op)
+		}
+		if len(s.args) < nargs[0] || len(s.args) > nargs[1] {
+			return nil, fmt.Errorf("line %d: %s takes %d to %d arguments", n, s.op, nargs[0], nargs[1])
+		}
+		if s.op == "sleep" {
+			if _, err := time.ParseDuration(s.args[0]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+		}
+		steps = append(steps, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty script")
+	}
+	return steps, nil
+}
+
+// expand replaces the variables in the arguments of the step.
+func (s step) expand(vars *strings.Replacer) []string {
+	res := make([]string, len(s.args))
+	for i, a := range s.args {
+		res[i] = vars.Replace(a)
+	}
+	return res
+}
+
+// arg returns the i'th argument, or "" if not given.
+func arg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+// request returns the request of the step, with the variables expanded.
+// It returns false for steps which are not requests.
+func (s step) request(t terminal, vars *strings.Replacer) (sip2.Message, bool) {
+	args := s.expand(vars)
+	now := time.Now()
+	var req sip2.TypedMessage
+	switch s.op {
+	case "login":
+		req = &sip2.LoginRequest{
+			UIDAlgorithm:  "0",
+			PWDAlgorithm:  "0",
+			LoginUserID:   args[0],
+			LoginPassword: args[1],
+			LocationCode:  arg(args, 2),
+		}
+	case "status":
+		req = &sip2.StatusRequest{MaxPrintWidth: 40, ProtocolVersion: "2.00"}
+	case "patron":
+		req = &sip2.PatronInformationRequest{
+			InstitutionID:    t.institution,
+			TerminalPassword: t.password,
+			TransactionDate:  now,
+			Summary:          strings.Repeat(" ", 10),
+			PatronIdentifier: args[0],
+			PatronPassword:   arg(args, 1),
+		}
+	case "checkout":
+		req = &sip2.CheckoutRequest{
+			InstitutionID:    t.institution,
+			TerminalPassword: t.password,
+			TransactionDate:  now,
+			NbDueDate:        now,
+			PatronIdentifier: args[0],
+			ItemIdentifier:   args[1],
+		}
+	case "checkin":
+		req = &sip2.CheckinRequest{
+			InstitutionID:    t.institution,
+			TerminalPassword: t.password,
+			TransactionDate:  now,
+			ReturnDate:       now,
+			ItemIdentifier:   args[0],
+			CurrentLocation:  arg(args, 1),
+		}
+	case "renew":
+		req = &sip2.RenewRequest{
+			InstitutionID:    t.institution,
+			TerminalPassword: t.password,
+			TransactionDate:  now,
+			NbDueDate:        now,
+			PatronIdentifier: args[0],
+			ItemIdentifier:   args[1],
+		}
+	case "item":
+		req = &sip2.ItemInformationRequest{
+			InstitutionID:    t.institution,
+			TerminalPassword: t.password,
+			TransactionDate:  now,
+			ItemIdentifier:   args[0],
+		}
+	default:
+		return sip2.Message{}, false
+	}
+	return req.Message(), true
+}
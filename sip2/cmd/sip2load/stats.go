@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+// buckets are the upper bounds of the latency histogram buckets.
+var buckets = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// opStats are the statistics of the requests of a script operation.
+type opStats struct {
+	latencies []time.Duration
+	errors    int // requests without a response
+	invalid   int // responses which do not conform to the protocol
+}
+
+// stats collects latencies, errors and protocol violations of the requests
+// sent by all terminals. It is safe for concurrent use.
+type stats struct {
+	mu         sync.Mutex
+	ops        map[string]*opStats
+	errors     map[string]int            // by error message
+	violations map[string]map[string]int // by response type, then violation
+}
+
+func newStats() *stats {
+	return &stats{
+		ops:        make(map[string]*opStats),
+		errors:     make(map[string]int),
+		violations: make(map[string]map[string]int),
+	}
+}
+
+// add records the outcome of a request.
+func (s *stats) add(op string, d time.Duration, resp sip2.Message, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.ops[op]
+	if !ok {
+		o = &opStats{}
+		s.ops[op] = o
+	}
+
+	var violations []string
+	switch {
+	case err != nil && resp.Type() == sip2.MsgUnknown:
+		o.errors++
+		s.errors[err.Error()]++
+		return
+	case err != nil:
+		// The ACS responded with the wrong message type.
+		violations = append(violations, err.Error())
+	default:
+		violations = resp.Validate()
+	}
+	o.latencies = append(o.latencies, d)
+	if len(violations) == 0 {
+		return
+	}
+	o.invalid++
+	t := resp.Type().String()
+	if s.violations[t] == nil {
+		s.violations[t] = make(map[string]int)
+	}
+	for _, v := range violations {
+		s.violations[t][v]++
+	}
+}
+
+// failed reports whether any request failed, or got a response which does
+// not conform to the protocol.
+func (s *stats) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.errors) > 0 || len(s.violations) > 0
+}
+
+// report writes a summary of the statistics, a latency histogram of each
+// operation, and the conformance report.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.ops))
+	total := 0
+	for name, o := range s.ops {
+		names = append(names, name)
+		total += len(o.latencies) + o.errors
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "%d requests in %v (%.1f/s)\n\n", total, elapsed.Round(time.Millisecond),
+		float64(total)/elapsed.Seconds())
+	fmt.Fprintf(w, "%-10s %7s %7s %7s %9s %9s %9s %9s %9s\n",
+		"operation", "count", "errors", "invalid", "min", "p50", "p95", "p99", "max")
+	for _, name := range names {
+		o := s.ops[name]
+		l := o.latencies
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		fmt.Fprintf(w, "%-10s %7d %7d %7d %9v %9v %9v %9v %9v\n", name,
+			len(l)+o.errors, o.errors, o.invalid,
+			percentile(l, 0), percentile(l, 50), percentile(l, 95), percentile(l, 99), percentile(l, 100))
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(w, "\n%s latency:\n", name)
+		histogram(w, s.ops[name].latencies)
+	}
+
+	if len(s.errors) > 0 {
+		fmt.Fprintf(w, "\nErrors:\n")
+		for _, e := range sortedKeys(s.errors) {
+			fmt.Fprintf(w, "  %6d  %s\n", s.errors[e], e)
+		}
+	}
+
+	fmt.Fprintf(w, "\nConformance:\n")
+	if len(s.violations) == 0 {
+		fmt.Fprintf(w, "  all responses have the required fields, with valid values\n")
+		return
+	}
+	types := make([]string, 0, len(s.violations))
+	for t := range s.violations {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(w, "  %s:\n", t)
+		for _, v := range sortedKeys(s.violations[t]) {
+			fmt.Fprintf(w, "  %6d  %s\n", s.violations[t][v], v)
+		}
+	}
+}
+
+// percentile returns the p'th percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted) - 1) * p / 100
+	return sorted[i].Round(10 * time.Microsecond)
+}
+
+// histogram writes the number of latencies in each bucket, with a bar
+// scaled to the largest bucket.
+func histogram(w io.Writer, latencies []time.Duration) {
+	counts := make([]int, len(buckets)+1)
+	for _, d := range latencies {
+		i := sort.Search(len(buckets), func(i int) bool { return d <= buckets[i] })
+		counts[i]++
+	}
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	if max == 0 {
+		return
+	}
+	for i, n := range counts {
+		label := "> " + buckets[len(buckets)-1].String()
+		if i < len(buckets) {
+			label = "<= " + buckets[i].String()
+		}
+		fmt.Fprintf(w, "  %9s %7d %s\n", label, n, strings.Repeat("#", n*40/max))
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
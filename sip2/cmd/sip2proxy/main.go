@@ -0,0 +1,94 @@
+// Command sip2proxy is a SIP2 gateway between self-service terminals and one
+// or more ACS servers.
+//
+// Usage:
+//
+//	sip2proxy -listen :6001 -upstream acs1:6001,acs2:6001 [flags]
+//
+// Fields can be rewritten on the way to the ACS with -rewrite, given as
+// CODE:FROM=TO, ex: "AO:kiosk=MAIN" to replace the institution ID "kiosk" with
+// "MAIN". The value is changed back in responses. An empty FROM replaces any
+// value. The flag can be repeated.
+//
+// Each transaction is logged as a structured log record to stderr.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+type rewrites []sip2.Rewrite
+
+func (r *rewrites) String() string {
+	return fmt.Sprint(*r)
+}
+
+func (r *rewrites) Set(s string) error {
+	code, rest, ok := strings.Cut(s, ":")
+	from, to, ok2 := strings.Cut(rest, "=")
+	if !ok || !ok2 {
+		return fmt.Errorf("want CODE:FROM=TO, got %q", s)
+	}
+	f, ok := sip2.FieldByCode(code)
+	if !ok {
+		return fmt.Errorf("unknown field code %q", code)
+	}
+	*r = append(*r, sip2.Rewrite{Field: f, From: from, To: to})
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sip2proxy: ")
+
+	var rw rewrites
+	listen := flag.String("listen", ":6001", "address to accept client connections on")
+	upstream := flag.String("upstream", "", "comma-separated addresses of the ACS servers")
+	balance := flag.String("balance", "failover", "how to choose an upstream: failover or roundrobin")
+	block := flag.String("block", "", "comma-separated codes of request messages to refuse, ex: 37,15")
+	jsonLog := flag.Bool("json", false, "log in JSON instead of key=value format")
+	flag.Var(&rw, "rewrite", "rewrite a field, as CODE:FROM=TO (repeatable)")
+	flag.Parse()
+
+	if *upstream == "" {
+		log.Fatal("missing -upstream")
+	}
+	p := sip2.NewProxy(strings.Split(*upstream, ",")...)
+	switch *balance {
+	case "failover":
+		p.Balance = sip2.Failover
+	case "roundrobin":
+		p.Balance = sip2.RoundRobin
+	default:
+		log.Fatalf("unknown -balance: %q", *balance)
+	}
+	if *block != "" {
+		for _, code := range strings.Split(*block, ",") {
+			t, ok := sip2.MsgTypeByCode(strings.TrimSpace(code))
+			if !ok {
+				log.Fatalf("unknown message code in -block: %q", code)
+			}
+			p.Block(t)
+		}
+	}
+	p.AddRewrite(rw...)
+	if *jsonLog {
+		p.Logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	} else {
+		p.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+
+	s, err := sip2.NewServerAddr(p, *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s, forwarding to %s", s.Addr(), *upstream)
+	log.Fatal(s.Run())
+}
@@ -0,0 +1,234 @@
+// Command sip2rec records and replays SIP2 traffic.
+//
+// In record mode, it acts as a transparent proxy between SIP2 clients and an
+// ACS, recording all messages with timestamps to a file:
+//
+//	sip2rec record -listen :6001 -upstream acs.example.org:6001 -o kiosk.rec
+//
+// Passwords are masked in the recording, unless the -passwords flag is
+// given, and the file is only readable by its owner.
+//
+// In replay mode, it resends the requests of each recorded client session to
+// an ACS, on a separate connection per session, and reports where the
+// responses differ from the recorded ones:
+//
+//	sip2rec replay -addr localhost:6001 kiosk.rec
+//
+// Recordings can also be made by a sip2.Server with a Recorder.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sip2rec: ")
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "record":
+		record(os.Args[2:])
+	case "replay":
+		replay(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n\tsip2rec record [flags]\n\tsip2rec replay [flags] recording\n")
+	os.Exit(2)
+}
+
+func record(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	listen := fs.String("listen", ":6001", "address to accept client connections on")
+	upstream := fs.String("upstream", "", "address of the ACS")
+	out := fs.String("o", "sip2.rec", "file to append the recording to")
+	passwords := fs.Bool("passwords", false, "record passwords (AD and CO) in plain text, instead of masked")
+	fs.Parse(args)
+	if *upstream == "" {
+		log.Fatal("missing -upstream")
+	}
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	rec := sip2.NewRecorder(f)
+	rec.RecordPasswords = *passwords
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("recording %s -> %s to %s", ln.Addr(), *upstream, *out)
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+		go proxy(c, *upstream, rec)
+	}
+}
+
+// proxy forwards messages between a client and the ACS, recording them.
+func proxy(client net.Conn, upstream string, rec *sip2.Recorder) {
+	defer client.Close()
+	acs, err := net.DialTimeout("tcp", upstream, 10*time.Second)
+	if err != nil {
+		log.Printf("[%v] %v", client.RemoteAddr(), err)
+		return
+	}
+	defer acs.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pipe(acs, client, client.RemoteAddr().String(), true, rec)
+		acs.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		pipe(client, acs, client.RemoteAddr().String(), false, rec)
+		client.Close()
+	}()
+	wg.Wait()
+}
+
+// pipe copies messages from src to dst until either fails.
+func pipe(dst io.Writer, src io.Reader, client string, request bool, rec *sip2.Recorder) {
+	r := bufio.NewReader(src)
+	for {
+		b, err := r.ReadBytes('\r')
+		if len(b) > 0 {
+			if err := rec.Record(client, request, b); err != nil {
+				log.Printf("recording failed: %v", err)
+			}
+			if _, err := dst.Write(b); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func replay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:6001", "address of the ACS to replay the requests to")
+	all := fs.Bool("all", false, "also compare timestamps, due dates and checksums")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout waiting for a response")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := sip2.ReadRecords(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var diffs int
+	for _, sess := range sip2.Sessions(records) {
+		n, err := replaySession(*addr, sess, *all, *timeout)
+		if err != nil {
+			log.Printf("session %s: %v", sess[0].Client, err)
+			diffs++
+		}
+		diffs += n
+	}
+	if diffs > 0 {
+		log.Printf("%d responses differ", diffs)
+		os.Exit(1)
+	}
+}
+
+// replaySession replays the requests of a session, and returns the number
+// of responses which differ from the recording.
+func replaySession(addr string, sess []sip2.Record, all bool, timeout time.Duration) (int, error) {
+	c, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	r := bufio.NewReader(c)
+
+	var diffs int
+	for i, rec := range sess {
+		if !rec.Request {
+			continue
+		}
+		req, err := rec.Message()
+		if err != nil {
+			return diffs, fmt.Errorf("recorded request %q: %v", rec.Data, err)
+		}
+		// The recorded response is the next message to the client, if any.
+		var want *sip2.Record
+		if i+1 < len(sess) && !sess[i+1].Request {
+			want = &sess[i+1]
+		}
+
+		c.SetDeadline(time.Now().Add(timeout))
+		if _, err := c.Write(rec.Data); err != nil {
+			return diffs, err
+		}
+		// The response is read even if none was recorded, for example if
+		// the session was cut off, so that it is not taken for the
+		// response to the next request.
+		b, err := r.ReadBytes('\r')
+		if err != nil {
+			return diffs, err
+		}
+		if want == nil {
+			continue
+		}
+
+		wantMsg, err := want.Message()
+		if err != nil {
+			return diffs, fmt.Errorf("recorded response %q: %v", want.Data, err)
+		}
+		gotMsg, err := sip2.Decode(b)
+		if err != nil {
+			return diffs, fmt.Errorf("response %q: %v", b, err)
+		}
+		var d []string
+		if all {
+			d = sip2.Diff(wantMsg, gotMsg)
+		} else {
+			d = sip2.Diff(wantMsg, gotMsg, sip2.FieldTransactionDate, sip2.FieldDateTimeSync,
+				sip2.FieldDueDate, sip2.FieldSequenceNumber, sip2.FieldChecksum)
+		}
+		if len(d) == 0 {
+			continue
+		}
+		diffs++
+		fmt.Printf("session %s, request %s\n", rec.Client, strings.TrimSpace(req.String()))
+		fmt.Printf("  recorded: %s\n  replayed: %s\n", strings.TrimSpace(wantMsg.String()), strings.TrimSpace(gotMsg.String()))
+		for _, line := range d {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	return diffs, nil
+}
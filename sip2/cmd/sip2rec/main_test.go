@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knakk/kbp/sip2"
+)
+
+func TestReplaySession(t *testing.T) {
+	rt := sip2.NewRouter()
+	s, err := sip2.NewServer(rt, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	login := sip2.NewMessage(sip2.MsgReqLogin).AddField(
+		sip2.Field{Type: sip2.FieldUIDAlgorithm, Value: "0"},
+		sip2.Field{Type: sip2.FieldPWDAlgorithm, Value: "0"},
+		sip2.Field{Type: sip2.FieldLoginUserID, Value: "user"},
+		sip2.Field{Type: sip2.FieldLoginPassword, Value: "****"},
+	)
+	status := sip2.NewMessage(sip2.MsgReqStatus).AddField(
+		sip2.Field{Type: sip2.FieldStatusCode, Value: "0"},
+		sip2.Field{Type: sip2.FieldMaxPrintWidth, Value: "040"},
+		sip2.Field{Type: sip2.FieldProtocolVersion, Value: "2.00"},
+	)
+	// The response to the login was not recorded.
+	sess := []sip2.Record{
+		{Client: "c", Request: true, Data: []byte(login.String())},
+		{Client: "c", Request: true, Data: []byte(status.String())},
+		{Client: "c", Request: false, Data: []byte(rt.Handle(status).String())},
+	}
+	diffs, err := replaySession(s.Addr().String(), sess, false, time.Second)
+	if err != nil || diffs != 0 {
+		t.Errorf("replaySession => %d, %v; want 0 differences", diffs, err)
+	}
+}
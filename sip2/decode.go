@@ -3,9 +3,14 @@ package sip2
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"unicode/utf8"
 )
 
+// ErrChecksum is returned by Decode when a message in error detection mode
+// has an invalid checksum.
+var ErrChecksum = errors.New("Decode: checksum mismatch")
+
 // Decode decodes a SIP message from a byte slice.
 //
 // Decode does not check if SIP message has all the required fields, other
@@ -15,8 +20,20 @@ import (
 // but fixed-length fields are guaranteed to have the required length.
 // To validate a Message call the Message.Validate() function.
 //
-// Fields which are not defined by the SIP protocol are ignored.
+// Fields which are not defined by the SIP protocol are ignored, unless
+// registered with RegisterExtension.
+//
+// If the message ends with a checksum (AZ), it is verified, and ErrChecksum
+// is returned if it doesn't match. The sequence number (AY) and checksum
+// are available as FieldSequenceNumber and FieldChecksum.
 func Decode(msg []byte) (Message, error) {
+	return DecodeCharset(msg, UTF8)
+}
+
+// DecodeCharset is like Decode, but decodes a message encoded in the given
+// Charset. Field values are transcoded to UTF-8. It returns an error if the
+// message contains bytes which are not valid in the Charset.
+func DecodeCharset(msg []byte, c Charset) (Message, error) {
 	var m Message
 
 	l := len(msg)
@@ -27,6 +44,29 @@ func Decode(msg []byte) (Message, error) {
 		msg = msg[:l]
 	}
 
+	// Verify and strip the error detection fields, if present.
+	var seq, sum string
+	if l >= 6 && string(msg[l-6:l-4]) == "AZ" {
+		sum = strings.ToUpper(string(msg[l-4:]))
+		if checksum(msg[:l-4]) != sum {
+			return m, ErrChecksum
+		}
+		l -= 6
+		if l >= 3 && string(msg[l-3:l-1]) == "AY" {
+			seq = string(msg[l-1 : l])
+			l -= 3
+		}
+		msg = msg[:l]
+	}
+
+	if c != UTF8 {
+		var err error
+		if msg, err = c.decode(msg); err != nil {
+			return m, err
+		}
+		l = len(msg)
+	}
+
 	if l < 2 {
 		return m, errors.New("Decode: message too short")
 	}
@@ -55,7 +95,11 @@ func Decode(msg []byte) (Message, error) {
 outer:
 	for len(msg) > p {
 		start := p + 2 // start of current field
-		f := codeToField[string(msg[p:start])]
+		code := string(msg[p:start])
+		f := codeToField[code]
+		if f == FieldUnknown {
+			f, _ = extensionField(code, m.typ)
+		}
 		p = start
 		if f == FieldUnknown {
 			// store unknown codes in message value
@@ -66,7 +110,7 @@ outer:
 			r, w := utf8.DecodeRune(msg[p:])
 			p += w
 			if r == '|' {
-				if repeatableField[f] {
+				if isRepeatable(f) {
 					m.repeateableFields[f] = append(m.repeateableFields[f], string(msg[start:p-1]))
 				} else {
 					m.fields[f] = string(msg[start : p-1])
@@ -76,7 +120,7 @@ outer:
 				}
 				continue outer
 			} else if p == l {
-				if repeatableField[f] {
+				if isRepeatable(f) {
 					m.repeateableFields[f] = append(m.repeateableFields[f], string(msg[start:l]))
 				} else {
 					m.fields[f] = string(msg[start:l])
@@ -87,5 +131,12 @@ outer:
 		}
 	}
 
+	if sum != "" {
+		m.fields[FieldChecksum] = sum
+		if seq != "" {
+			m.fields[FieldSequenceNumber] = seq
+		}
+	}
+
 	return m, nil
 }
@@ -188,3 +188,86 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorDetection(t *testing.T) {
+	for _, want := range []string{"96AZFEF6\r", "97AZFEF5\r"} {
+		msg, err := Decode([]byte(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := msg.Encode(&b); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != want {
+			t.Errorf("got %q; want %q", b.String(), want)
+		}
+	}
+
+	msg := NewMessage(MsgRespLogin).AddField(
+		Field{Type: FieldOK, Value: "1"},
+		Field{Type: FieldSequenceNumber, Value: "4"},
+	)
+	var b bytes.Buffer
+	if err := msg.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "941AY4AZFDF9\r"; b.String() != want {
+		t.Errorf("got %q; want %q", b.String(), want)
+	}
+
+	got, err := Decode(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Field(FieldOK) != "1" || got.Field(FieldSequenceNumber) != "4" || got.Field(FieldChecksum) != "FDF9" {
+		t.Errorf("Decode(%q) got %v", b.String(), got.fields)
+	}
+
+	if _, err := Decode([]byte("940AY4AZFDF9\r")); err != ErrChecksum {
+		t.Errorf("Decode with bad checksum got %v; want %v", err, ErrChecksum)
+	}
+}
+
+func TestValidateCurrencyType(t *testing.T) {
+	// The currency type is fixed-length in Fee Paid requests only.
+	feePaid := func(currency string) Message {
+		return NewMessage(MsgReqFeePaid).AddField(
+			Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+			Field{Type: FieldFeeType, Value: "01"},
+			Field{Type: FieldPaymentType, Value: "00"},
+			Field{Type: FieldCurrenyType, Value: currency},
+			Field{Type: FieldFeeAmount, Value: "10.00"},
+			Field{Type: FieldInstitutionID, Value: "x"},
+			Field{Type: FieldPatronIdentifier, Value: "p1"},
+		)
+	}
+	itemInfo := func(currency string) Message {
+		return NewMessage(MsgRespItemInformation).AddField(
+			Field{Type: FieldCirulationStatus, Value: "01"},
+			Field{Type: FieldSecurityMarker, Value: "00"},
+			Field{Type: FieldFeeType, Value: "01"},
+			Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+			Field{Type: FieldItemIdentifier, Value: "i1"},
+			Field{Type: FieldCurrenyType, Value: currency},
+		)
+	}
+
+	tests := []struct {
+		msg   Message
+		valid bool
+	}{
+		{feePaid("NOK"), true},
+		{feePaid("EURO"), false},
+		{feePaid(""), false},
+		{itemInfo("NOK"), true},
+		{itemInfo("EURO"), true},
+		{itemInfo(""), true},
+	}
+	for _, test := range tests {
+		if errs := test.msg.Validate(); (len(errs) == 0) != test.valid {
+			t.Errorf("%v with currency type %q: Validate() => %v; want valid: %v",
+				test.msg.Type(), test.msg.Field(FieldCurrenyType), errs, test.valid)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package sip2
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Extension describes a field which is not defined by the SIP2 protocol,
+// such as the vendor-specific fields sent by some self-service terminals,
+// or a field defined by the protocol which is used in messages where the
+// protocol does not define it.
+//
+// Decode ignores fields which are not defined by the protocol, and Encode
+// leaves out fields which are not defined for the message type, unless they
+// are registered with RegisterExtension.
+type Extension struct {
+	// Code is the 2-character field code, ex: "BG".
+	Code string
+
+	// Repeatable is true if the field may occur several times in a message.
+	// The values of a repeatable field are available with Message.Fields.
+	Repeatable bool
+
+	// Messages are the message types the field may occur in. If empty, the
+	// field may occur in messages of any type.
+	Messages []msgType
+}
+
+// applies reports whether the extension field may occur in messages of
+// the given type.
+func (e Extension) applies(t msgType) bool {
+	if len(e.Messages) == 0 {
+		return true
+	}
+	for _, mt := range e.Messages {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// extensions is the registry of extension fields. Extension fields which are
+// not defined by the protocol have field types following the last field type
+// defined by the protocol.
+var extensions = struct {
+	sync.RWMutex
+	byCode  map[string]fieldType
+	byField map[fieldType]Extension
+	order   []fieldType // in order of registration
+	next    fieldType   // field type of the next new field
+}{
+	byCode:  make(map[string]fieldType),
+	byField: make(map[fieldType]Extension),
+	next:    FieldChecksum + 1,
+}
+
+// RegisterExtension registers an extension field, and returns the field type
+// used to access its value, ex: with Message.Field, or to add it to a Message
+// with Message.AddField. If the code is that of a field defined by the
+// protocol, its field type is returned, and the field is allowed in the
+// given message types in addition to those defined by the protocol.
+//
+// Registered fields are kept by Decode in messages of the types the field
+// applies to, and encoded after the fields defined by the protocol, in the
+// order they were registered.
+//
+// It returns an error if the code is not 2 characters, is already registered,
+// or is that of a field defined by the protocol with another repeatability.
+func RegisterExtension(e Extension) (fieldType, error) {
+	if len(e.Code) != 2 {
+		return FieldUnknown, fmt.Errorf("sip2: invalid extension field code: %q", e.Code)
+	}
+	f, defined := codeToField[e.Code]
+	if defined && e.Repeatable != repeatableField[f] {
+		return FieldUnknown, fmt.Errorf("sip2: extension field code %q is used by %v, with other repeatability", e.Code, f)
+	}
+	e.Messages = append([]msgType(nil), e.Messages...)
+
+	extensions.Lock()
+	defer extensions.Unlock()
+	if _, ok := extensions.byCode[e.Code]; ok {
+		return FieldUnknown, fmt.Errorf("sip2: extension field code %q already registered", e.Code)
+	}
+	if !defined {
+		f = extensions.next
+		extensions.next++
+	}
+	extensions.byCode[e.Code] = f
+	extensions.byField[f] = e
+	extensions.order = append(extensions.order, f)
+	return f, nil
+}
+
+// MustRegisterExtension is like RegisterExtension, but panics if the field
+// cannot be registered. It simplifies declaring extension fields as package
+// variables:
+//
+//	var FieldShelfMark = sip2.MustRegisterExtension(sip2.Extension{Code: "XS"})
+func MustRegisterExtension(e Extension) fieldType {
+	f, err := RegisterExtension(e)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// extensionField returns the extension field with the given code, if it is
+// registered and applies to the message type.
+func extensionField(code string, t msgType) (fieldType, bool) {
+	extensions.RLock()
+	defer extensions.RUnlock()
+	f, ok := extensions.byCode[code]
+	if !ok || !extensions.byField[f].applies(t) {
+		return FieldUnknown, false
+	}
+	return f, true
+}
+
+// isRepeatable reports whether the field, defined by the protocol or
+// registered as an extension, is repeatable.
+func isRepeatable(f fieldType) bool {
+	if f <= FieldChecksum {
+		return repeatableField[f]
+	}
+	extensions.RLock()
+	defer extensions.RUnlock()
+	return extensions.byField[f].Repeatable
+}
+
+// encodeExtensions writes the extension fields of the message which apply
+// to its type, and are not already encoded as defined by the protocol.
+func (m Message) encodeExtensions(bw *bytes.Buffer) {
+	extensions.RLock()
+	defer extensions.RUnlock()
+	for _, f := range extensions.order {
+		e := extensions.byField[f]
+		if !e.applies(m.typ) || isDefined(m.typ, f) {
+			continue
+		}
+		if e.Repeatable {
+			for _, v := range m.repeateableFields[f] {
+				bw.WriteString(e.Code + v + "|")
+			}
+		} else if v, ok := m.fields[f]; ok {
+			bw.WriteString(e.Code + v + "|")
+		}
+	}
+}
+
+// isDefined reports whether the field is defined by the protocol for the
+// message type.
+func isDefined(t msgType, f fieldType) bool {
+	if isOptional(t, f) {
+		return true
+	}
+	for _, rf := range msgDefinitions[t].RequiredVar {
+		if rf == f {
+			return true
+		}
+	}
+	for _, rf := range msgDefinitions[t].RequiredFixed {
+		if rf == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package sip2
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Extension fields used in the tests.
+var (
+	fieldTestLocation = MustRegisterExtension(Extension{Code: "XL"})
+	fieldTestCategory = MustRegisterExtension(Extension{Code: "PB", Repeatable: true})
+	fieldTestCheckin  = MustRegisterExtension(Extension{Code: "XB", Messages: []msgType{MsgReqCheckin, MsgRespCheckin}})
+	// The owner is defined only in Item Information responses.
+	fieldTestOwner = MustRegisterExtension(Extension{Code: "BG", Messages: []msgType{MsgRespCheckout}})
+)
+
+func TestExtensionDecodeEncode(t *testing.T) {
+	tests := []struct {
+		input  string
+		fields map[fieldType][]string
+		output string
+	}{
+		{
+			"09N20160822    15345020160822    153450AP|AOx|ABi1|ACp|XLshelf 3|PBa|PBb|XBbin 2|ZZunknown|",
+			map[fieldType][]string{
+				fieldTestLocation:   {"shelf 3"},
+				fieldTestCategory:   {"a", "b"},
+				fieldTestCheckin:    {"bin 2"},
+				FieldItemIdentifier: {"i1"},
+			},
+			"09N20160822    15345020160822    153450AP|AOx|ABi1|ACp|XLshelf 3|PBa|PBb|XBbin 2|\r",
+		},
+		{
+			// XB does not apply to Checkout requests, and is dropped.
+			"11YN20160822    15345020160822    153450AOx|AAp1|ABi1|ACp|XBbin 2|XLdesk|",
+			map[fieldType][]string{
+				fieldTestLocation: {"desk"},
+				fieldTestCheckin:  nil,
+			},
+			"11YN20160822    15345020160822    153450AOx|AAp1|ABi1|ACp|XLdesk|\r",
+		},
+	}
+
+	for _, test := range tests {
+		m, err := Decode([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for f, want := range test.fields {
+			var got []string
+			if isRepeatable(f) {
+				got = m.Fields(f)
+			} else if v, ok := m.FieldOK(f); ok {
+				got = []string{v}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode(%q) %v => %q; want %q", test.input, f, got, want)
+			}
+		}
+		if got := m.String(); got != test.output {
+			t.Errorf("Decode(%q).String() =>\n%q; want\n%q", test.input, got, test.output)
+		}
+	}
+
+	if fieldTestOwner != FieldOwner {
+		t.Errorf("registered BG as %v; want FieldOwner", fieldTestOwner)
+	}
+	resp := NewMessage(MsgRespCheckout).AddField(
+		Field{Type: FieldOK, Value: "1"},
+		Field{Type: FieldRenewalOK, Value: "N"},
+		Field{Type: FieldMagneticMedia, Value: "N"},
+		Field{Type: FieldDesentisize, Value: "Y"},
+		Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+		Field{Type: FieldInstitutionID, Value: "x"},
+		Field{Type: FieldPatronIdentifier, Value: "p1"},
+		Field{Type: FieldItemIdentifier, Value: "i1"},
+		Field{Type: FieldTitleIdentifier, Value: "t"},
+		Field{Type: FieldDueDate, Value: "20160901    000000"},
+		Field{Type: FieldOwner, Value: "Main library"},
+	)
+	if want := "121NNY20160822    153450AOx|AAp1|ABi1|AJt|AH20160901    000000|BGMain library|\r"; resp.String() != want {
+		t.Errorf("checkout response with owner =>\n%q; want\n%q", resp.String(), want)
+	}
+
+	if f, ok := FieldByCode("PB"); !ok || f != fieldTestCategory {
+		t.Errorf("FieldByCode(\"PB\") => %v, %v; want %v, true", f, ok, fieldTestCategory)
+	}
+	m := NewMessage(MsgRespCheckin).AddField(Field{Type: fieldTestCategory, Value: "x"}, Field{Type: fieldTestCategory, Value: "y"})
+	if got := m.Field(fieldTestCategory); got != "x" {
+		t.Errorf("Field of repeatable extension => %q; want \"x\"", got)
+	}
+}
+
+func TestRegisterExtensionErrors(t *testing.T) {
+	for _, e := range []Extension{
+		{Code: "X"},
+		{Code: "AOX"},
+		{Code: "AO", Repeatable: true}, // institution ID is not repeatable
+		{Code: "XL"},                   // already registered
+	} {
+		if f, err := RegisterExtension(e); err == nil {
+			t.Errorf("RegisterExtension(%+v) => %v, nil error; want error", e, f)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+//go:build ignore
+// +build ignore
+
+// This program generates messages.go, with a typed struct for each of the
+// message types in msgDefinitions. It reads the message definitions from the
+// source of msgdef.go and sip.go. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// goFields maps field types to the name and kind of the corresponding struct
+// field. The kind is the name of the fieldEncoder/fieldDecoder methods used to
+// convert the value, and for enums the Go type, ex: "int:CirculationStatus".
+// Fields not listed are strings, named after the fieldType.
+var goFields = map[string]struct{ name, kind string }{
+	"FieldAlert":                 {"", "bool"},
+	"FieldAvialable":             {"Available", "bool"},
+	"FieldCardRetained":          {"", "bool"},
+	"FieldChargedItemsCount":     {"", "int"},
+	"FieldFineItemsCount":        {"", "int"},
+	"FieldHoldItemsCount":        {"", "int"},
+	"FieldOverdueItemsCount":     {"", "int"},
+	"FieldRecallItemsCount":      {"", "int"},
+	"FieldUnavailableHoldsCount": {"", "int"},
+	"FieldCheckinOK":             {"", "bool"},
+	"FieldCheckoutOK":            {"", "bool"},
+	"FieldCirulationStatus":      {"CirculationStatus", "int:CirculationStatus"},
+	"FieldDateTimeSync":          {"", "time"},
+	"FieldDesentisize":           {"Desensitize", "string:YesNoUnknown"},
+	"FieldEndSession":            {"", "bool"},
+	"FieldHoldMode":              {"", "string:HoldMode"},
+	"FieldItemPropertiesOK":      {"", "bit"},
+	"FieldLanguage":              {"", "int:Language"},
+	"FieldMagneticMedia":         {"", "string:YesNoUnknown"},
+	"FieldMaxPrintWidth":         {"", "int"},
+	"FieldNbDueDate":             {"", "time"},
+	"FieldNoBlock":               {"", "bool"},
+	"FieldOffLineOK":             {"", "bool"},
+	"FieldOK":                    {"", "bit"},
+	"FieldOnLineStatus":          {"", "bool"},
+	"FieldPaymentAccepted":       {"", "bool"},
+	"FieldPaymentType":           {"", "int:PaymentType"},
+	"FieldRenewalOK":             {"", "bool"},
+	"FieldRenewedCount":          {"", "int"},
+	"FieldResentisize":           {"Resensitize", "bool"},
+	"FieldRetriesAllowed":        {"", "int"},
+	"FieldReturnDate":            {"", "time"},
+	"FieldRenewalPolicy":         {"", "bool"},
+	"FieldSecurityMarker":        {"", "int:SecurityMarker"},
+	"FieldStatusCode":            {"", "int:StatusCode"},
+	"FieldStatusUpdateOK":        {"", "bool"},
+	"FieldThirdPartyAllowd":      {"ThirdPartyAllowed", "bool"},
+	"FieldTimeoutPeriod":         {"", "int"},
+	"FieldTransactionDate":       {"", "time"},
+	"FieldUnrenewedCount":        {"", "int"},
+	"FieldCurrenyType":           {"CurrencyType", "string"},
+	"FieldCancel":                {"", "bool"},
+	"FieldValidPatron":           {"", "bool"},
+	"FieldFeeAcknowledged":       {"", "bool"},
+	"FieldQueuePosition":         {"", "int"},
+	"FieldFeeType":               {"", "int:FeeType"},
+	"FieldExpirationDate":        {"", "time"},
+	"FieldHoldType":              {"", "int:HoldType"},
+	"FieldHoldItemsLimit":        {"", "int"},
+	"FieldOverdueItemsLimit":     {"", "int"},
+	"FieldChargedItemsLimit":     {"", "int"},
+	"FieldHoldQueueLength":       {"", "int"},
+	"FieldSecurityInhibit":       {"", "bool"},
+	"FieldRecallDate":            {"", "time"},
+	"FieldMediaType":             {"", "int:MediaType"},
+	"FieldHoldPickupDate":        {"", "time"},
+	"FieldValidPatronPassword":   {"", "bool"},
+}
+
+type msgDef struct {
+	typ    string
+	code   string
+	fields []field
+}
+
+type field struct {
+	typ      string
+	required bool
+	repeat   bool
+}
+
+func main() {
+	fset := token.NewFileSet()
+	sip, err := parser.ParseFile(fset, "sip.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	def, err := parser.ParseFile(fset, "msgdef.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	vars := make(map[string]*ast.CompositeLit)
+	ast.Inspect(def, func(n ast.Node) bool {
+		if vs, ok := n.(*ast.ValueSpec); ok {
+			for i, name := range vs.Names {
+				if lit, ok := vs.Values[i].(*ast.CompositeLit); ok {
+					vars[name.Name] = lit
+				}
+			}
+		}
+		return true
+	})
+
+	codes := make(map[string]string)
+	for _, e := range vars["msgToCode"].Elts {
+		kv := e.(*ast.KeyValueExpr)
+		codes[ident(kv.Key)] = strings.Trim(kv.Value.(*ast.BasicLit).Value, `"`)
+	}
+	repeatable := make(map[string]bool)
+	for _, e := range vars["repeatableField"].Elts {
+		repeatable[ident(e.(*ast.KeyValueExpr).Key)] = true
+	}
+	defs := make(map[string]msgDef)
+	for _, e := range vars["msgDefinitions"].Elts {
+		kv := e.(*ast.KeyValueExpr)
+		d := msgDef{typ: ident(kv.Key), code: codes[ident(kv.Key)]}
+		seen := make(map[string]bool)
+		for _, part := range kv.Value.(*ast.CompositeLit).Elts {
+			part := part.(*ast.KeyValueExpr)
+			for _, f := range part.Value.(*ast.CompositeLit).Elts {
+				if seen[ident(f)] {
+					continue
+				}
+				seen[ident(f)] = true
+				d.fields = append(d.fields, field{
+					typ:      ident(f),
+					required: ident(part.Key) != "OptionalVar",
+					repeat:   repeatable[ident(f)],
+				})
+			}
+		}
+		defs[d.typ] = d
+	}
+
+	// Output the message types in the order they are declared in sip.go.
+	var order []string
+	ast.Inspect(sip, func(n ast.Node) bool {
+		if vs, ok := n.(*ast.ValueSpec); ok {
+			for _, name := range vs.Names {
+				if _, ok := defs[name.Name]; ok {
+					order = append(order, name.Name)
+				}
+			}
+		}
+		return true
+	})
+
+	var body bytes.Buffer
+	for _, t := range order {
+		writeMsg(&body, defs[t])
+	}
+	writeNewTyped(&body, order)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by go generate; DO NOT EDIT.\n\npackage sip2\n\n")
+	if bytes.Contains(body.Bytes(), []byte("time.Time")) {
+		b.WriteString("import \"time\"\n\n")
+	}
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v\n%s", err, b.Bytes())
+	}
+	if err := os.WriteFile("messages.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// words splits a camel-cased name into words, ex: "Patron Status" for "PatronStatus".
+func words(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func ident(e ast.Expr) string {
+	return e.(*ast.Ident).Name
+}
+
+// structName returns the name of the typed struct for a message type,
+// ex: CheckoutRequest for MsgReqCheckout.
+func structName(t string) string {
+	if strings.HasPrefix(t, "MsgReq") {
+		return strings.TrimPrefix(t, "MsgReq") + "Request"
+	}
+	return strings.TrimPrefix(t, "MsgResp") + "Response"
+}
+
+// goField returns the struct field name, the Go type and the conversion
+// method for a field type.
+func goField(f field) (name, typ, method string) {
+	gf := goFields[f.typ]
+	name = gf.name
+	if name == "" {
+		name = strings.TrimPrefix(f.typ, "Field")
+	}
+	kind := gf.kind
+	if kind == "" {
+		kind = "string"
+	}
+	method, typ = kind, kind
+	if i := strings.IndexByte(kind, ':'); i > 0 {
+		method, typ = kind[:i], kind[i+1:]
+	}
+	switch {
+	case f.repeat:
+		return name, "[]string", "strings"
+	case typ == "time":
+		typ = "time.Time"
+	case typ == "bit":
+		typ = "bool"
+	case typ == "bool" && !f.required:
+		typ = "*bool"
+	}
+	return name, typ, method
+}
+
+func writeMsg(b *bytes.Buffer, d msgDef) {
+	name := structName(d.typ)
+	kind := "Request"
+	if strings.HasPrefix(d.typ, "MsgResp") {
+		kind = "Response"
+	}
+	fmt.Fprintf(b, "// %s is the %s %s message (%s).\n", name, words(strings.TrimSuffix(name, kind)), strings.ToLower(kind), d.code)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, f := range d.fields {
+		n, t, _ := goField(f)
+		fmt.Fprintf(b, "\t%s %s\n", n, t)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "// Message converts the %s to a Message.\n", name)
+	fmt.Fprintf(b, "func (r %s) Message() Message {\n", name)
+	if len(d.fields) == 0 {
+		fmt.Fprintf(b, "\treturn NewMessage(%s)\n}\n\n", d.typ)
+	} else {
+		fmt.Fprintf(b, "\te := fieldEncoder{NewMessage(%s)}\n", d.typ)
+		for _, f := range d.fields {
+			n, t, method := goField(f)
+			if !f.required && !f.repeat {
+				method = "opt" + strings.ToUpper(method[:1]) + method[1:]
+			}
+			arg := "r." + n
+			switch {
+			case method == "string" && t != "string", method == "optString" && t != "string":
+				arg = "string(" + arg + ")"
+			case (method == "int" || method == "optInt") && t != "int":
+				arg = "int(" + arg + ")"
+			}
+			fmt.Fprintf(b, "\te.%s(%s, %s)\n", method, f.typ, arg)
+		}
+		b.WriteString("\treturn e.m\n}\n\n")
+	}
+
+	fmt.Fprintf(b, "// FromMessage sets the fields of the %s from a Message.\n", name)
+	fmt.Fprintf(b, "func (r *%s) FromMessage(m Message) error {\n", name)
+	b.WriteString("\td := fieldDecoder{m: m}\n")
+	fmt.Fprintf(b, "\tif !d.checkType(%s) {\n\t\treturn d.err\n\t}\n", d.typ)
+	for _, f := range d.fields {
+		n, t, method := goField(f)
+		var v string
+		switch {
+		case method == "strings":
+			v = fmt.Sprintf("m.Fields(%s)", f.typ)
+		case method == "string":
+			v = fmt.Sprintf("m.Field(%s)", f.typ)
+			if t != "string" {
+				v = t + "(" + v + ")"
+			}
+		case method == "bool" && !f.required:
+			v = fmt.Sprintf("d.optBool(%s)", f.typ)
+		default:
+			v = fmt.Sprintf("d.%s(%s)", method, f.typ)
+			if method == "int" && t != "int" {
+				v = t + "(" + v + ")"
+			}
+		}
+		fmt.Fprintf(b, "\tr.%s = %s\n", n, v)
+	}
+	b.WriteString("\treturn d.err\n}\n\n")
+}
+
+func writeNewTyped(b *bytes.Buffer, order []string) {
+	b.WriteString("// newTyped returns a new typed struct for the message type, or nil if there is none.\n")
+	b.WriteString("func newTyped(t msgType) TypedMessage {\n\tswitch t {\n")
+	for _, t := range order {
+		fmt.Fprintf(b, "\tcase %s:\n\t\treturn &%s{}\n", t, structName(t))
+	}
+	b.WriteString("\t}\n\treturn nil\n}\n")
+}
@@ -0,0 +1,79 @@
+package sip2
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Logger receives the log records of a Server. Each record has a message,
+// and attributes as alternating keys and values, like the methods of
+// *slog.Logger, which implements the interface. Implementations must be
+// safe for concurrent use.
+type Logger interface {
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// stdLogger is a Logger writing to the standard logger of package log,
+// with the attributes formatted as key=value.
+type stdLogger struct{}
+
+func (stdLogger) Info(msg string, args ...any)  { stdLog("INFO", msg, args) }
+func (stdLogger) Warn(msg string, args ...any)  { stdLog("WARN", msg, args) }
+func (stdLogger) Error(msg string, args ...any) { stdLog("ERROR", msg, args) }
+
+func stdLog(level, msg string, args []any) {
+	var b strings.Builder
+	b.WriteString(level + " " + msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%q", args[i], fmt.Sprint(args[i+1]))
+	}
+	log.Print(b.String())
+}
+
+// maskedFields are the fields with values which must not be logged.
+var maskedFields = []fieldType{FieldPatronPassword, FieldLoginPassword}
+
+// mask returns the message as encoded, with the values of passwords
+// replaced by asterisks, for logging.
+func mask(m Message) string {
+	masked := false
+	for _, f := range maskedFields {
+		if v, ok := m.fields[f]; ok && v != "" {
+			if !masked {
+				m = m.clone()
+				masked = true
+			}
+			m.fields[f] = "****"
+		}
+	}
+	return strings.TrimSuffix(m.String(), "\r")
+}
+
+// maskedRaw matches password fields in messages which cannot be decoded:
+// after a delimiter, or at the start of the variable-length fields.
+var maskedRaw = regexp.MustCompile(`(^|\|)(AD|CO)[^|\r]+`)
+
+// maskRaw returns the message with passwords replaced by asterisks, for
+// logging messages which cannot be decoded.
+func maskRaw(b []byte) string {
+	s := strings.TrimSuffix(string(b), "\r")
+	// The first variable-length field follows the fixed-length fields
+	// without a delimiter, so the fixed-length part is set aside.
+	var fixed string
+	if len(s) >= 2 {
+		if t := codeToMsg[s[:2]]; t != MsgUnknown {
+			n := 2
+			for _, f := range msgDefinitions[t].RequiredFixed {
+				n += fixedFieldLengths[f]
+			}
+			if n <= len(s) {
+				fixed, s = s[:n], s[n:]
+			}
+		}
+	}
+	return fixed + maskedRaw.ReplaceAllString(s, "$1$2****")
+}
@@ -0,0 +1,92 @@
+package sip2
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testLogger is a Logger keeping the records logged.
+type testLogger struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (l *testLogger) log(level, msg string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, fmt.Sprint(append([]any{level, msg}, args...)...))
+}
+
+func (l *testLogger) Info(msg string, args ...any)  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...any)  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...any) { l.log("ERROR", msg, args) }
+
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.records, "\n")
+}
+
+func TestServerLogger(t *testing.T) {
+	var l testLogger
+	s, err := NewServer(NewRouter(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Log = true
+	s.Logger = &l
+	go s.Run()
+	defer s.Close()
+
+	c := NewClient(s.Addr().String(), testMF)
+	if err := c.Login("user", "secret1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PatronInformation("p1", "secret2"); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	logs := l.String()
+	for _, want := range []string{"CNuser|CO****|", "AAp1|AC|AD****|", "sip2 response"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("log does not contain %q:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "secret") {
+		t.Errorf("log contains password:\n%s", logs)
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		in, want string
+		rawOnly  bool // not in the field order of encoded messages
+	}{
+		{"9300CNuser|COpass|CPhere|\r", "9300CNuser|CO****|CPhere|", false},
+		{"6300020261019    130134          AOx|AAp1|AC|ADpass|\r", "6300020261019    130134          AOx|AAp1|AC|AD****|", false},
+		{"9300CNuser|CO|\r", "9300CNuser|CO|", false},
+		{"9300COsecret|CNuser|\r", "9300CO****|CNuser|", true},
+		{"9300ADsecret|ADx\r", "9300AD****|AD****", true},
+	}
+	for _, test := range tests {
+		if got := maskRaw([]byte(test.in)); got != test.want {
+			t.Errorf("maskRaw(%q) => %q; want %q", test.in, got, test.want)
+		}
+		if test.rawOnly {
+			continue
+		}
+		m, err := Decode([]byte(test.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mask(m); got != test.want {
+			t.Errorf("mask(%q) => %q; want %q", test.in, got, test.want)
+		}
+		if m.String() != test.in {
+			t.Errorf("mask modified the message: %q", m.String())
+		}
+	}
+}
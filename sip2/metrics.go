@@ -0,0 +1,168 @@
+package sip2
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds in seconds of the buckets of the
+// request latency histograms.
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects counters and latency histograms of the requests handled
+// by a Server, by message type and client IP. It is an http.Handler serving
+// the metrics in the Prometheus text exposition format.
+//
+// Errors are counted by reason: "checksum" and "decode" for requests which
+// cannot be decoded, "invalid" for requests violating the protocol (when
+// Server.Validation is enabled), and "encode" for responses which cannot be
+// encoded.
+//
+// It is safe for concurrent use.
+type Metrics struct {
+	mu       sync.Mutex
+	requests map[metricKey]*latencyHistogram
+	errors   map[errorKey]int
+	clients  int // connected clients
+}
+
+type metricKey struct {
+	typ    msgType
+	client string
+}
+
+type errorKey struct {
+	reason string
+	client string
+}
+
+type latencyHistogram struct {
+	counts []int // by bucket, not cumulative; the last is +Inf
+	sum    float64
+	n      int
+}
+
+// NewMetrics returns a new Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: make(map[metricKey]*latencyHistogram),
+		errors:   make(map[errorKey]int),
+	}
+}
+
+// observe records a request of the given type from the client, handled in d.
+func (m *Metrics) observe(t msgType, client string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := metricKey{t, client}
+	h, ok := m.requests[k]
+	if !ok {
+		h = &latencyHistogram{counts: make([]int, len(latencyBuckets)+1)}
+		m.requests[k] = h
+	}
+	s := d.Seconds()
+	i := sort.SearchFloat64s(latencyBuckets, s)
+	h.counts[i]++
+	h.sum += s
+	h.n++
+}
+
+// countError counts an error, such as a message which cannot be decoded.
+func (m *Metrics) countError(reason, client string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[errorKey{reason, client}]++
+}
+
+func (m *Metrics) connected(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients += delta
+}
+
+// Requests returns the number of requests of the given type from the given
+// client IP. If client is empty, requests from all clients are counted.
+func (m *Metrics) Requests(t msgType, client string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for k, h := range m.requests {
+		if k.typ == t && (client == "" || k.client == client) {
+			n += h.n
+		}
+	}
+	return n
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+
+	keys := make([]metricKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].typ != keys[j].typ {
+			return keys[i].typ < keys[j].typ
+		}
+		return keys[i].client < keys[j].client
+	})
+
+	b.WriteString("# HELP sip2_requests_total Requests handled, by message type and client.\n")
+	b.WriteString("# TYPE sip2_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "sip2_requests_total{type=%q,client=%q} %d\n", k.typ.String(), k.client, m.requests[k].n)
+	}
+
+	b.WriteString("# HELP sip2_request_duration_seconds Time taken to handle requests, by message type and client.\n")
+	b.WriteString("# TYPE sip2_request_duration_seconds histogram\n")
+	for _, k := range keys {
+		h := m.requests[k]
+		labels := fmt.Sprintf("type=%q,client=%q", k.typ.String(), k.client)
+		cum := 0
+		for i, le := range latencyBuckets {
+			cum += h.counts[i]
+			fmt.Fprintf(&b, "sip2_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, cum)
+		}
+		fmt.Fprintf(&b, "sip2_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.n)
+		fmt.Fprintf(&b, "sip2_request_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(&b, "sip2_request_duration_seconds_count{%s} %d\n", labels, h.n)
+	}
+
+	ekeys := make([]errorKey, 0, len(m.errors))
+	for k := range m.errors {
+		ekeys = append(ekeys, k)
+	}
+	sort.Slice(ekeys, func(i, j int) bool {
+		if ekeys[i].reason != ekeys[j].reason {
+			return ekeys[i].reason < ekeys[j].reason
+		}
+		return ekeys[i].client < ekeys[j].client
+	})
+	b.WriteString("# HELP sip2_errors_total Messages which could not be handled, by reason and client.\n")
+	b.WriteString("# TYPE sip2_errors_total counter\n")
+	for _, k := range ekeys {
+		fmt.Fprintf(&b, "sip2_errors_total{reason=%q,client=%q} %d\n", k.reason, k.client, m.errors[k])
+	}
+
+	b.WriteString("# HELP sip2_clients_connected Clients currently connected.\n")
+	b.WriteString("# TYPE sip2_clients_connected gauge\n")
+	fmt.Fprintf(&b, "sip2_clients_connected %d\n", m.clients)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
@@ -0,0 +1,71 @@
+package sip2
+
+import (
+	"bufio"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	s, err := NewServer(NewRouter(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Metrics = m
+	go s.Run()
+	defer s.Close()
+
+	c := NewClient(s.Addr().String(), testMF)
+	if err := c.Login("user", "pass", ""); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Close()
+
+	// A request with a bad checksum, which is asked resent.
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("9900302.00AY1AZ0000\r"))
+	if _, err := bufio.NewReader(conn).ReadBytes('\r'); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	ip := remoteIP(conn.LocalAddr())
+	if n := m.Requests(MsgReqStatus, ip); n != 3 {
+		t.Errorf("Requests(MsgReqStatus, %s) => %d; want 3", ip, n)
+	}
+	if n := m.Requests(MsgReqLogin, ""); n != 1 {
+		t.Errorf("Requests(MsgReqLogin, \"\") => %d; want 1", n)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE sip2_requests_total counter\n",
+		`sip2_requests_total{type="MsgReqStatus",client="` + ip + `"} 3` + "\n",
+		`sip2_requests_total{type="MsgReqLogin",client="` + ip + `"} 1` + "\n",
+		"# TYPE sip2_request_duration_seconds histogram\n",
+		`sip2_request_duration_seconds_bucket{type="MsgReqStatus",client="` + ip + `",le="+Inf"} 3` + "\n",
+		`sip2_request_duration_seconds_count{type="MsgReqStatus",client="` + ip + `"} 3` + "\n",
+		`sip2_errors_total{reason="checksum",client="` + ip + `"} 1` + "\n",
+		"sip2_clients_connected ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type: %q", ct)
+	}
+}
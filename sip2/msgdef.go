@@ -95,7 +95,8 @@ var (
 			RequiredVar: []fieldType{},
 			OptionalVar: []fieldType{},
 		},
-		MsgReqResend: msgDef{},
+		MsgReqResend:  msgDef{},
+		MsgRespResend: msgDef{},
 		MsgReqLogin: msgDef{
 			RequiredFixed: []fieldType{
 				FieldUIDAlgorithm,
@@ -142,9 +143,9 @@ var (
 				FieldFeeType,
 				FieldPaymentType,
 				FieldCurrenyType,
-				FieldFeeAmount,
 			},
 			RequiredVar: []fieldType{
+				FieldFeeAmount,
 				FieldInstitutionID,
 				FieldPatronIdentifier,
 			},
@@ -552,6 +553,7 @@ var (
 		FieldCheckinOK:             1,
 		FieldCheckoutOK:            1,
 		FieldCirulationStatus:      2,
+		FieldCurrenyType:           3, // only checked where it is fixed-length: in Fee Paid requests
 		FieldDateTimeSync:          18,
 		FieldDesentisize:           1,
 		FieldEndSession:            1,
@@ -591,7 +593,7 @@ var (
 	rxpYesNo         = regexp.MustCompile(`Y|N`)
 	rxpDigits        = regexp.MustCompile(`\d+$`)
 	rxpDigitsOrBlank = regexp.MustCompile(`[\d\s]+$`)
-	rxpTimestamp     = regexp.MustCompile(`\d{8}[\sZ\d]{4}\d{6}`) // TODO verify with ANSI standard X3.30 for date and X3.43 for time.
+	rxpTimestamp     = regexp.MustCompile(`\d{8}[\sZ\d]{4}\d{6}|\s{18}`) // blank if not set. TODO verify with ANSI standard X3.30 for date and X3.43 for time.
 
 	// fieldValidation defines the allowed character patterns for fields. If a fieldType
 	// is not present in this map, any string is allowed.
@@ -624,7 +626,7 @@ var (
 		FieldPatronStatus:          regexp.MustCompile(`[\s|Y]{14}`),
 		FieldPaymentAccepted:       rxpYesNo,
 		FieldPaymentType:           rxpDigits, // {2}
-		FieldProtocolVersion:       regexp.MustCompile(`\d\.\d{20`),
+		FieldProtocolVersion:       regexp.MustCompile(`\d\.\d{2}`),
 		FieldRenewalOK:             rxpYesNo,
 		FieldRenewedCount:          rxpDigits, // {4}
 		FieldResentisize:           rxpYesNo,
@@ -639,6 +641,24 @@ var (
 		FieldTimeoutPeriod:         rxpDigits, // {3}
 		FieldTransactionDate:       rxpTimestamp,
 		FieldUnrenewedCount:        rxpDigits, // {4}
+		FieldItemPropertiesOK:      regexp.MustCompile(`0|1`),
+		FieldFeeType:               rxpDigits, // {2}
+		// Variable-length fields:
+		FieldCancel:              rxpYesNo,
+		FieldValidPatron:         rxpYesNo,
+		FieldValidPatronPassword: rxpYesNo,
+		FieldFeeAcknowledged:     rxpYesNo,
+		FieldSecurityInhibit:     rxpYesNo,
+		FieldExpirationDate:      rxpTimestamp,
+		FieldRecallDate:          rxpTimestamp,
+		FieldHoldPickupDate:      rxpTimestamp,
+		FieldQueuePosition:       rxpDigits,
+		FieldHoldType:            rxpDigits, // {1}
+		FieldHoldItemsLimit:      rxpDigits, // {4}
+		FieldOverdueItemsLimit:   rxpDigits, // {4}
+		FieldChargedItemsLimit:   rxpDigits, // {4}
+		FieldHoldQueueLength:     rxpDigits,
+		FieldMediaType:           rxpDigits, // {3}
 	}
 
 	// minMsgLength defines the minimum length needed for a Message to be able to contain
@@ -675,6 +695,7 @@ var (
 		MsgRespHold:              28, // 2+1+1+18+3+3
 		MsgRespRenew:             39, // 2+1+1+1+1+18+3+3+3+3+3
 		MsgRespRenewAll:          32, // 2+1+4+4+18+3
+		MsgRespResend:            2,  // 2
 	}
 
 	repeatableField = map[fieldType]bool{
@@ -721,6 +742,7 @@ var (
 		MsgRespHold:              "16",
 		MsgRespRenew:             "30",
 		MsgRespRenewAll:          "66",
+		MsgRespResend:            "96",
 	}
 
 	codeToMsg = map[string]msgType{
@@ -754,6 +776,7 @@ var (
 		"16": MsgRespHold,
 		"30": MsgRespRenew,
 		"66": MsgRespRenewAll,
+		"96": MsgRespResend,
 	}
 
 	fieldToCode = map[fieldType]string{
@@ -889,3 +912,23 @@ func isOptional(mt msgType, ft fieldType) bool {
 	}
 	return false
 }
+
+// MsgTypeByCode returns the message type with the given 2-character code,
+// ex: MsgReqCheckout for "11".
+func MsgTypeByCode(code string) (msgType, bool) {
+	t, ok := codeToMsg[code]
+	return t, ok
+}
+
+// FieldByCode returns the variable-length field with the given 2-character
+// code, ex: FieldInstitutionID for "AO", or the registered extension field
+// with the code.
+func FieldByCode(code string) (fieldType, bool) {
+	if f, ok := codeToField[code]; ok {
+		return f, true
+	}
+	extensions.RLock()
+	defer extensions.RUnlock()
+	f, ok := extensions.byCode[code]
+	return f, ok
+}
@@ -4,9 +4,9 @@ package sip2
 
 import "fmt"
 
-const _msgType_name = "MsgUnknownMsgReqPatronStatusMsgReqCheckoutMsgReqCheckinMsgReqBlockPatronMsgReqStatusMsgReqResendMsgReqLoginMsgReqPatronInformationMsgReqEndPatronSessionMsgReqFeePaidMsgReqItemInformationMsgReqItemStatusUpdateMsgReqPatronEnableMsgReqHoldMsgReqRenewMsgReqRenewAllMsgRespPatronStatusMsgRespCheckoutMsgRespCheckinMsgRespStatusMsgRespLoginMsgRespPatronInformationMsgRespEndPatronSessionMsgRespFeePaidMsgRespItemInformationMsgRespItemStatusUpdateMsgRespPatronEnableMsgRespHoldMsgRespRenewMsgRespRenewAll"
+const _msgType_name = "MsgUnknownMsgReqPatronStatusMsgReqCheckoutMsgReqCheckinMsgReqBlockPatronMsgReqStatusMsgReqResendMsgReqLoginMsgReqPatronInformationMsgReqEndPatronSessionMsgReqFeePaidMsgReqItemInformationMsgReqItemStatusUpdateMsgReqPatronEnableMsgReqHoldMsgReqRenewMsgReqRenewAllMsgRespPatronStatusMsgRespCheckoutMsgRespCheckinMsgRespStatusMsgRespLoginMsgRespPatronInformationMsgRespEndPatronSessionMsgRespFeePaidMsgRespItemInformationMsgRespItemStatusUpdateMsgRespPatronEnableMsgRespHoldMsgRespRenewMsgRespRenewAllMsgRespResend"
 
-var _msgType_index = [...]uint16{0, 10, 28, 42, 55, 72, 84, 96, 107, 130, 152, 165, 186, 208, 226, 236, 247, 261, 280, 295, 309, 322, 334, 358, 381, 395, 417, 440, 459, 470, 482, 497}
+var _msgType_index = [...]uint16{0, 10, 28, 42, 55, 72, 84, 96, 107, 130, 152, 165, 186, 208, 226, 236, 247, 261, 280, 295, 309, 322, 334, 358, 381, 395, 417, 440, 459, 470, 482, 497, 510}
 
 func (i msgType) String() string {
 	if i < 0 || i >= msgType(len(_msgType_index)-1) {
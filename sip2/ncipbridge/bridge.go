@@ -0,0 +1,542 @@
+// Package ncipbridge provides a sip2.Handler which serves SIP2 circulation
+// requests by translating them into NCIP requests to a library system.
+//
+// The following SIP2 requests are translated:
+//
+//	Checkout            -> CheckOutItem
+//	Checkin             -> CheckInItem
+//	Renew               -> RenewItem
+//	Patron Information  -> LookupUser
+//	Item Information    -> LookupItem
+//
+// Problems reported in the NCIP responses are shown as screen messages in the
+// SIP2 responses. Other SIP2 requests are passed on to a fallback Handler.
+package ncipbridge
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/knakk/kbp/ncip"
+	"github.com/knakk/kbp/sip2"
+)
+
+// Bridge is a sip2.Handler which translates SIP2 requests into NCIP requests,
+// sends them to an NCIP endpoint over HTTP, and translates the NCIP responses
+// back into SIP2 responses.
+type Bridge struct {
+	// URL is the address of the NCIP endpoint.
+	URL string
+
+	// FromAgency and ToAgency are the agency IDs of the bridge and the
+	// library system, sent in the InitiationHeader of each request.
+	FromAgency string
+	ToAgency   string
+
+	// Client is the HTTP client used to send the NCIP requests. If nil,
+	// http.DefaultClient is used. Either way, requests time out after 30
+	// seconds, as with an ncip.Client.
+	Client *http.Client
+
+	// Messages maps NCIP problem types (e.g. "Unknown User") to the screen
+	// messages shown to patrons. Problems without a mapping are shown by
+	// their detail, or by their type if the detail is empty.
+	Messages map[string]string
+
+	// Fallback handles all requests which are not translated, such as
+	// Login and SC Status. Defaults to a sip2.Router.
+	Fallback sip2.Handler
+
+	// Logger, if not nil, gets a log record of each failed NCIP request.
+	Logger sip2.Logger
+}
+
+// NCIP scheme values used by the Bridge.
+const (
+	elementBibliographicDescription = "Bibliographic Description"
+	elementCirculationStatus        = "Circulation Status"
+	elementHoldQueueLength          = "Hold Queue Length"
+	elementLocation                 = "Location"
+	elementNameInformation          = "Name Information"
+	elementUserAddressInformation   = "User Address Information"
+	elementBlockOrTrap              = "Block Or Trap"
+)
+
+// errUnavailable is returned by check when the NCIP endpoint cannot be
+// reached, or does not respond properly. Patrons are shown msgUnavailable.
+var errUnavailable = errors.New("ncipbridge: service unavailable")
+
+const msgUnavailable = "Service unavailable"
+
+// New returns a new Bridge sending NCIP requests to the endpoint at the
+// given URL.
+func New(url, fromAgency, toAgency string) *Bridge {
+	return &Bridge{
+		URL:        url,
+		FromAgency: fromAgency,
+		ToAgency:   toAgency,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		Fallback:   sip2.NewRouter(),
+	}
+}
+
+// Handle translates Checkout, Checkin, Renew, Patron Information and Item
+// Information requests, and passes on any other request to b.Fallback.
+func (b *Bridge) Handle(req sip2.Message) sip2.Message {
+	switch req.Type() {
+	case sip2.MsgReqCheckout:
+		return b.checkout(req)
+	case sip2.MsgReqCheckin:
+		return b.checkin(req)
+	case sip2.MsgReqRenew:
+		return b.renew(req)
+	case sip2.MsgReqPatronInformation:
+		return b.patronInformation(req)
+	case sip2.MsgReqItemInformation:
+		return b.itemInformation(req)
+	}
+	if b.Fallback == nil {
+		return sip2.NewRouter().Handle(req)
+	}
+	return b.Fallback.Handle(req)
+}
+
+func (b *Bridge) checkout(m sip2.Message) sip2.Message {
+	var req sip2.CheckoutRequest
+	err := req.FromMessage(m)
+	resp := sip2.CheckoutResponse{
+		MagneticMedia:    sip2.Unknown,
+		Desensitize:      sip2.No,
+		TransactionDate:  time.Now(),
+		InstitutionID:    req.InstitutionID,
+		PatronIdentifier: req.PatronIdentifier,
+		ItemIdentifier:   req.ItemIdentifier,
+	}
+	if err != nil {
+		resp.ScreenMessage = []string{"Invalid request"}
+		return resp.Message()
+	}
+
+	nreq := &ncip.CheckOutItem{
+		UserId:              &ncip.UserId{UserIdentifierValue: req.PatronIdentifier},
+		AuthenticationInput: authInput(req.PatronPassword),
+		ItemId:              ncip.ItemId{ItemIdentifierValue: req.ItemIdentifier},
+		DesiredDateDue:      desiredDateDue(req.NoBlock, req.NbDueDate),
+		ItemElementType:     elements(elementBibliographicDescription),
+	}
+	nresp, err := b.client().CheckOutItem(nreq)
+	if b.check(nreq, err) != nil {
+		resp.ScreenMessage = []string{msgUnavailable}
+		return resp.Message()
+	}
+
+	resp.TitleIdentifier = title(nresp.ItemOptionalFields)
+	resp.ScreenMessage = b.screenMessages(nresp.Problem)
+	if len(nresp.Problem) == 0 {
+		resp.OK = true
+		resp.Desensitize = sip2.Yes
+		resp.DueDate = sip2Date(nresp.DateDue)
+	}
+	return resp.Message()
+}
+
+func (b *Bridge) checkin(m sip2.Message) sip2.Message {
+	var req sip2.CheckinRequest
+	err := req.FromMessage(m)
+	resp := sip2.CheckinResponse{
+		MagneticMedia:   sip2.Unknown,
+		TransactionDate: time.Now(),
+		InstitutionID:   req.InstitutionID,
+		ItemIdentifier:  req.ItemIdentifier,
+	}
+	if err != nil {
+		resp.ScreenMessage = []string{"Invalid request"}
+		return resp.Message()
+	}
+
+	nreq := &ncip.CheckInItem{
+		ItemId:          ncip.ItemId{ItemIdentifierValue: req.ItemIdentifier},
+		ItemElementType: elements(elementBibliographicDescription, elementLocation),
+	}
+	nresp, err := b.client().CheckInItem(nreq)
+	if b.check(nreq, err) != nil {
+		resp.ScreenMessage = []string{msgUnavailable}
+		return resp.Message()
+	}
+
+	resp.TitleIdentifier = title(nresp.ItemOptionalFields)
+	resp.PermanentLocation = location(nresp.ItemOptionalFields)
+	resp.ScreenMessage = b.screenMessages(nresp.Problem)
+	if nresp.UserId != nil {
+		resp.PatronIdentifier = nresp.UserId.UserIdentifierValue
+	}
+	if ri := nresp.RoutingInformation; ri != nil {
+		// The item must be sent somewhere else, e.g. to fill a hold.
+		resp.Alert = true
+		resp.SortBin = ri.Destination.BinNumber
+	}
+	if len(nresp.Problem) == 0 {
+		resp.OK = true
+		resp.Resensitize = true
+	}
+	return resp.Message()
+}
+
+func (b *Bridge) renew(m sip2.Message) sip2.Message {
+	var req sip2.RenewRequest
+	err := req.FromMessage(m)
+	resp := sip2.RenewResponse{
+		MagneticMedia:    sip2.Unknown,
+		Desensitize:      sip2.Unknown,
+		TransactionDate:  time.Now(),
+		InstitutionID:    req.InstitutionID,
+		PatronIdentifier: req.PatronIdentifier,
+		ItemIdentifier:   req.ItemIdentifier,
+		TitleIdentifier:  req.TitleIdentifier,
+	}
+	if err != nil {
+		resp.ScreenMessage = []string{"Invalid request"}
+		return resp.Message()
+	}
+
+	nreq := &ncip.RenewItem{
+		UserId:              &ncip.UserId{UserIdentifierValue: req.PatronIdentifier},
+		AuthenticationInput: authInput(req.PatronPassword),
+		ItemId:              ncip.ItemId{ItemIdentifierValue: req.ItemIdentifier},
+		DesiredDateDue:      desiredDateDue(req.NoBlock, req.NbDueDate),
+	}
+	nresp, err := b.client().RenewItem(nreq)
+	if b.check(nreq, err) != nil {
+		resp.ScreenMessage = []string{msgUnavailable}
+		return resp.Message()
+	}
+
+	resp.ScreenMessage = b.screenMessages(nresp.Problem)
+	if len(nresp.Problem) == 0 {
+		resp.OK = true
+		resp.RenewalOK = true
+		resp.DueDate = sip2Date(nresp.DateDue)
+	}
+	return resp.Message()
+}
+
+func (b *Bridge) patronInformation(m sip2.Message) sip2.Message {
+	var req sip2.PatronInformationRequest
+	err := req.FromMessage(m)
+	invalid := false
+	resp := sip2.PatronInformationResponse{
+		PatronStatus:     strings.Repeat(" ", 14),
+		Language:         req.Language,
+		TransactionDate:  time.Now(),
+		InstitutionID:    req.InstitutionID,
+		PatronIdentifier: req.PatronIdentifier,
+		ValidPatron:      &invalid,
+	}
+	if err != nil {
+		resp.ScreenMessage = []string{"Invalid request"}
+		return resp.Message()
+	}
+
+	nreq := &ncip.LookupUser{
+		UserId:              &ncip.UserId{UserIdentifierValue: req.PatronIdentifier},
+		AuthenticationInput: authInput(req.PatronPassword),
+		UserElementType: elements(elementNameInformation,
+			elementUserAddressInformation, elementBlockOrTrap),
+		LoanedItemsDesired:    &ncip.LoanedItemsDesired{},
+		RequestedItemsDesired: &ncip.RequestedItemsDesired{},
+	}
+	nresp, err := b.client().LookupUser(nreq)
+	if b.check(nreq, err) != nil {
+		resp.ScreenMessage = []string{msgUnavailable}
+		return resp.Message()
+	}
+
+	resp.ScreenMessage = b.screenMessages(nresp.Problem)
+	valid := !hasProblem(nresp.Problem, ncip.ErrUnknownUser)
+	resp.ValidPatron = &valid
+	if req.PatronPassword != "" {
+		validPassword := valid && !hasProblem(nresp.Problem, ncip.ErrUserAuthenticationFailed)
+		resp.ValidPatronPassword = &validPassword
+	}
+	if len(nresp.Problem) > 0 {
+		return resp.Message()
+	}
+
+	for _, item := range nresp.LoanedItem {
+		resp.ChargedItems = append(resp.ChargedItems, item.ItemId.ItemIdentifierValue)
+	}
+	resp.ChargedItemsCount = len(nresp.LoanedItem)
+	if len(nresp.LoanedItemsCount) > 0 {
+		resp.ChargedItemsCount = 0
+		for _, c := range nresp.LoanedItemsCount {
+			resp.ChargedItemsCount += c.LoanedItemCountValue
+		}
+	}
+	for _, item := range nresp.RequestedItem {
+		if item.ItemId != nil {
+			resp.HoldItems = append(resp.HoldItems, item.ItemId.ItemIdentifierValue)
+		} else {
+			resp.HoldItems = append(resp.HoldItems, item.Title)
+		}
+	}
+	resp.HoldItemsCount = len(nresp.RequestedItem)
+	if len(nresp.RequestedItemsCount) > 0 {
+		resp.HoldItemsCount = 0
+		for _, c := range nresp.RequestedItemsCount {
+			resp.HoldItemsCount += c.RequestedItemCountValue
+		}
+	}
+
+	if uf := nresp.UserOptionalFields; uf != nil {
+		resp.PersonalName = personalName(uf.NameInformation)
+		for _, addr := range uf.UserAddressInformation {
+			if ea := addr.ElectronicAddress; ea != nil {
+				switch ea.ElectronicAddressType.Value {
+				case "mailto":
+					resp.EmailAddress = ea.ElectronicAddressData
+				case "tel":
+					resp.HomePhoneNumber = ea.ElectronicAddressData
+				}
+			}
+			if pa := addr.PhysicalAddress; pa != nil && resp.HomeAddress == "" {
+				resp.HomeAddress = physicalAddress(pa)
+			}
+		}
+		if len(uf.BlockOrTrap) > 0 {
+			// Deny charge, renewal, recall and hold privileges.
+			resp.PatronStatus = "YYYY" + strings.Repeat(" ", 10)
+			for _, bt := range uf.BlockOrTrap {
+				resp.ScreenMessage = append(resp.ScreenMessage, b.screenMessage(bt.BlockOrTrapType.Value, ""))
+			}
+		}
+	}
+	return resp.Message()
+}
+
+func (b *Bridge) itemInformation(m sip2.Message) sip2.Message {
+	var req sip2.ItemInformationRequest
+	err := req.FromMessage(m)
+	resp := sip2.ItemInformationResponse{
+		CirculationStatus: sip2.CirculationOther,
+		SecurityMarker:    sip2.SecurityMarkerOther,
+		FeeType:           sip2.FeeOther,
+		TransactionDate:   time.Now(),
+		ItemIdentifier:    req.ItemIdentifier,
+	}
+	if err != nil {
+		resp.ScreenMessage = []string{"Invalid request"}
+		return resp.Message()
+	}
+
+	nreq := &ncip.LookupItem{
+		ItemId: &ncip.ItemId{ItemIdentifierValue: req.ItemIdentifier},
+		ItemElementType: elements(elementBibliographicDescription,
+			elementCirculationStatus, elementHoldQueueLength, elementLocation),
+	}
+	nresp, err := b.client().LookupItem(nreq)
+	if b.check(nreq, err) != nil {
+		resp.ScreenMessage = []string{msgUnavailable}
+		return resp.Message()
+	}
+
+	resp.ScreenMessage = b.screenMessages(nresp.Problem)
+	resp.HoldPickupDate = parseDate(nresp.HoldPickupDate)
+	resp.RecallDate = parseDate(nresp.DateRecalled)
+	if f := nresp.ItemOptionalFields; f != nil {
+		resp.TitleIdentifier = title(f)
+		resp.PermanentLocation = location(f)
+		if f.CirculationStatus != nil {
+			resp.CirculationStatus = circulationStatus(f.CirculationStatus.Value)
+		}
+		if f.HoldQueueLength != nil {
+			resp.HoldQueueLength = *f.HoldQueueLength
+		}
+	}
+	return resp.Message()
+}
+
+// client returns the NCIP client for the endpoint.
+func (b *Bridge) client() *ncip.Client {
+	c := ncip.NewClient(b.URL, b.FromAgency, b.ToAgency)
+	c.HTTPClient = b.Client
+	return c
+}
+
+// check returns the error of sending the NCIP request, if the endpoint did
+// not respond with a response to it. Problems reported in the response are
+// not errors; they are shown to the patron. Errors are logged, and reported
+// as errUnavailable.
+func (b *Bridge) check(req ncip.Request, err error) error {
+	var pe *ncip.ProblemError
+	if err == nil || errors.As(err, &pe) && pe.Response != nil {
+		return nil
+	}
+	if b.Logger != nil {
+		b.Logger.Warn("ncip request failed",
+			"url", b.URL,
+			"request", fmt.Sprintf("%T", req),
+			"error", err.Error())
+	}
+	return errUnavailable
+}
+
+// screenMessages returns the screen messages for the given problems.
+func (b *Bridge) screenMessages(problems []ncip.Problem) []string {
+	var res []string
+	for _, p := range problems {
+		res = append(res, b.screenMessage(p.ProblemType.Value, p.ProblemDetail))
+	}
+	return res
+}
+
+func (b *Bridge) screenMessage(problemType, detail string) string {
+	if msg, ok := b.Messages[problemType]; ok {
+		return msg
+	}
+	if detail != "" {
+		return detail
+	}
+	return problemType
+}
+
+func hasProblem(problems []ncip.Problem, target ncip.Problem) bool {
+	for _, p := range problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func elements(names ...string) []ncip.SchemeValue {
+	res := make([]ncip.SchemeValue, len(names))
+	for i, name := range names {
+		res[i] = ncip.SchemeValue{Value: name}
+	}
+	return res
+}
+
+func authInput(password string) []ncip.AuthenticationInput {
+	if password == "" {
+		return nil
+	}
+	return []ncip.AuthenticationInput{{
+		AuthenticationInputData:      password,
+		AuthenticationDataFormatType: ncip.SchemeValue{Value: "text"},
+		AuthenticationInputType:      ncip.SchemeValue{Value: "PIN"},
+	}}
+}
+
+func title(f *ncip.ItemOptionalFields) string {
+	if f == nil || f.BibliographicDescription == nil {
+		return ""
+	}
+	return f.BibliographicDescription.Title
+}
+
+// location returns the name of the first location of the item, with the
+// levels of the name separated by slashes.
+func location(f *ncip.ItemOptionalFields) string {
+	if f == nil || len(f.Location) == 0 {
+		return ""
+	}
+	var names []string
+	for _, l := range f.Location[0].LocationName.LocationNameInstance {
+		names = append(names, l.LocationNameValue)
+	}
+	return strings.Join(names, "/")
+}
+
+func personalName(n *ncip.NameInformation) string {
+	if n == nil || n.PersonalNameInformation == nil {
+		return ""
+	}
+	if n.PersonalNameInformation.UnstructuredPersonalUserName != "" {
+		return n.PersonalNameInformation.UnstructuredPersonalUserName
+	}
+	s := n.PersonalNameInformation.StructuredPersonalUserName
+	if s.GivenName == "" {
+		return s.Surname
+	}
+	return s.Surname + ", " + s.GivenName
+}
+
+func physicalAddress(pa *ncip.PhysicalAddress) string {
+	if pa.UnstructuredAddress != nil {
+		return pa.UnstructuredAddress.UnstructuredAddressData
+	}
+	if sa := pa.StructuredAddress; sa != nil {
+		var parts []string
+		for _, s := range []string{sa.Line1, sa.Line2, strings.TrimSpace(sa.PostalCode + " " + sa.Locality), sa.Country} {
+			if s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
+}
+
+// circulationStatuses maps the values of the NCIP Circulation Status scheme
+// to SIP2 circulation statuses.
+var circulationStatuses = map[string]sip2.CirculationStatus{
+	"Available On Shelf":                   sip2.CirculationAvailable,
+	"Available For Pickup":                 sip2.CirculationWaitingOnHold,
+	"Claimed Returned Or Never Borrowed":   sip2.CirculationClaimedReturned,
+	"In Process":                           sip2.CirculationInProcess,
+	"In Transit Between Library Locations": sip2.CirculationInTransit,
+	"Lost":                                 sip2.CirculationLost,
+	"Missing":                              sip2.CirculationMissing,
+	"On Loan":                              sip2.CirculationCharged,
+	"On Order":                             sip2.CirculationOnOrder,
+	"Waiting To Be Reshelved":              sip2.CirculationWaitingReshelving,
+}
+
+func circulationStatus(v string) sip2.CirculationStatus {
+	if s, ok := circulationStatuses[v]; ok {
+		return s
+	}
+	return sip2.CirculationOther
+}
+
+// parseDate parses a NCIP xs:dateTime, returning the zero time if it is
+// empty or invalid. SIP2 dates are in local time, so is the result.
+func parseDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Local()
+		}
+	}
+	return time.Time{}
+}
+
+// sip2Date converts a NCIP xs:dateTime into a SIP2 date, or returns it
+// as is if it cannot be parsed.
+func sip2Date(s string) string {
+	t := parseDate(s)
+	if t.IsZero() {
+		return s
+	}
+	return t.Format(sip2.DateLayout)
+}
+
+// desiredDateDue returns the due date to request for a SIP2 transaction. The
+// no block due date is only set by the SC for transactions done offline,
+// with no block; otherwise it is a placeholder, and the library system
+// decides the due date.
+func desiredDateDue(noBlock bool, nbDueDate time.Time) string {
+	if !noBlock {
+		return ""
+	}
+	return formatDate(nbDueDate)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
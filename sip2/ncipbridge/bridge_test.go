@@ -0,0 +1,294 @@
+package ncipbridge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/knakk/kbp/ncip"
+	"github.com/knakk/kbp/sip2"
+)
+
+// testNCIP is a fake NCIP endpoint, knowing the user "p1" with PIN "1234"
+// and the items "i1" and "i2".
+func testNCIP(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := ncip.DecodeRequest(r.Body)
+		if err != nil {
+			t.Errorf("fake NCIP endpoint: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var resp ncip.Response
+		switch req := req.(type) {
+		case *ncip.CheckOutItem:
+			if h := req.InitiationHeader; h == nil || h.FromAgencyId.AgencyId.Value != "sc" || h.ToAgencyId.AgencyId.Value != "lib" {
+				t.Errorf("CheckOutItem with InitiationHeader %+v; want agencies sc and lib", req.InitiationHeader)
+			}
+			r := &ncip.CheckOutItemResponse{
+				DateDue: "2026-11-16T00:00:00Z",
+				ItemOptionalFields: &ncip.ItemOptionalFields{
+					BibliographicDescription: &ncip.BibliographicDescription{Title: "Sult"},
+				},
+			}
+			if req.ItemId.ItemIdentifierValue == "i2" {
+				r.Problem = []ncip.Problem{{
+					ProblemType:   ncip.SchemeValue{Value: "Item Does Not Circulate"},
+					ProblemDetail: "Reference copy",
+				}}
+			}
+			resp = r
+		case *ncip.CheckInItem:
+			resp = &ncip.CheckInItemResponse{
+				UserId: &ncip.UserId{UserIdentifierValue: "p1"},
+				RoutingInformation: &ncip.RoutingInformation{
+					RoutingInstructions: "Hold for p2",
+					Destination:         ncip.Destination{BinNumber: "3"},
+				},
+				ItemOptionalFields: &ncip.ItemOptionalFields{
+					BibliographicDescription: &ncip.BibliographicDescription{Title: "Sult"},
+					Location: []ncip.Location{{
+						LocationType: ncip.SchemeValue{Value: "Permanent Location"},
+						LocationName: ncip.LocationName{LocationNameInstance: []ncip.LocationNameInstance{
+							{LocationNameLevel: 1, LocationNameValue: "Main"},
+							{LocationNameLevel: 2, LocationNameValue: "Fiction"},
+						}},
+					}},
+				},
+			}
+		case *ncip.RenewItem:
+			resp = &ncip.RenewItemResponse{
+				Problem: []ncip.Problem{{ProblemType: ncip.SchemeValue{Value: "Maximum Renewals Exceeded"}}},
+			}
+		case *ncip.LookupUser:
+			r := &ncip.LookupUserResponse{}
+			switch {
+			case req.UserId.UserIdentifierValue != "p1":
+				r.Problem = []ncip.Problem{{ProblemType: ncip.SchemeValue{Value: "Unknown User"}}}
+			case len(req.AuthenticationInput) != 1 || req.AuthenticationInput[0].AuthenticationInputData != "1234":
+				r.Problem = []ncip.Problem{{ProblemType: ncip.SchemeValue{Value: "User Authentication Failed"}}}
+			default:
+				r.LoanedItem = []ncip.LoanedItem{{ItemId: ncip.ItemId{ItemIdentifierValue: "i1"}}}
+				r.RequestedItem = []ncip.RequestedItem{{ItemId: &ncip.ItemId{ItemIdentifierValue: "i3"}}}
+				r.UserOptionalFields = &ncip.UserOptionalFields{
+					NameInformation: &ncip.NameInformation{
+						PersonalNameInformation: &ncip.PersonalNameInformation{
+							StructuredPersonalUserName: ncip.StructuredPersonalUserName{GivenName: "Knut", Surname: "Hamsun"},
+						},
+					},
+					UserAddressInformation: []ncip.UserAddressInformation{{
+						ElectronicAddress: &ncip.ElectronicAddress{
+							ElectronicAddressType: ncip.SchemeValue{Value: "mailto"},
+							ElectronicAddressData: "knut@example.org",
+						},
+					}},
+				}
+			}
+			resp = r
+		case *ncip.LookupItem:
+			queue := 2
+			resp = &ncip.LookupItemResponse{
+				HoldPickupDate: "2026-10-25T00:00:00Z",
+				ItemOptionalFields: &ncip.ItemOptionalFields{
+					BibliographicDescription: &ncip.BibliographicDescription{Title: "Sult"},
+					CirculationStatus:        &ncip.SchemeValue{Value: "On Loan"},
+					HoldQueueLength:          &queue,
+				},
+			}
+		default:
+			t.Errorf("fake NCIP endpoint: unexpected request %T", req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		if err := ncip.EncodeResponse(w, resp); err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestBridge(t *testing.T) {
+	srv := testNCIP(t)
+	defer srv.Close()
+	b := New(srv.URL, "sc", "lib")
+	b.Messages = map[string]string{"Maximum Renewals Exceeded": "Cannot renew again"}
+
+	now := time.Now()
+	tests := []struct {
+		req  sip2.TypedMessage
+		want sip2.TypedMessage
+	}{
+		{
+			&sip2.CheckoutRequest{InstitutionID: "x", PatronIdentifier: "p1", ItemIdentifier: "i1", TransactionDate: now},
+			&sip2.CheckoutResponse{
+				OK:               true,
+				MagneticMedia:    sip2.Unknown,
+				Desensitize:      sip2.Yes,
+				InstitutionID:    "x",
+				PatronIdentifier: "p1",
+				ItemIdentifier:   "i1",
+				TitleIdentifier:  "Sult",
+				DueDate:          time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC).Local().Format(sip2.DateLayout),
+			},
+		},
+		{
+			&sip2.CheckoutRequest{InstitutionID: "x", PatronIdentifier: "p1", ItemIdentifier: "i2", TransactionDate: now},
+			&sip2.CheckoutResponse{
+				MagneticMedia:    sip2.Unknown,
+				Desensitize:      sip2.No,
+				InstitutionID:    "x",
+				PatronIdentifier: "p1",
+				ItemIdentifier:   "i2",
+				TitleIdentifier:  "Sult",
+				ScreenMessage:    []string{"Reference copy"},
+			},
+		},
+		{
+			&sip2.CheckinRequest{InstitutionID: "x", ItemIdentifier: "i1", TransactionDate: now, ReturnDate: now},
+			&sip2.CheckinResponse{
+				OK:                true,
+				Resensitize:       true,
+				MagneticMedia:     sip2.Unknown,
+				Alert:             true,
+				InstitutionID:     "x",
+				ItemIdentifier:    "i1",
+				PermanentLocation: "Main/Fiction",
+				TitleIdentifier:   "Sult",
+				SortBin:           "3",
+				PatronIdentifier:  "p1",
+			},
+		},
+		{
+			&sip2.RenewRequest{InstitutionID: "x", PatronIdentifier: "p1", ItemIdentifier: "i1", TransactionDate: now},
+			&sip2.RenewResponse{
+				MagneticMedia:    sip2.Unknown,
+				Desensitize:      sip2.Unknown,
+				InstitutionID:    "x",
+				PatronIdentifier: "p1",
+				ItemIdentifier:   "i1",
+				ScreenMessage:    []string{"Cannot renew again"},
+			},
+		},
+		{
+			&sip2.PatronInformationRequest{InstitutionID: "x", PatronIdentifier: "p1", PatronPassword: "1234", TransactionDate: now, Summary: "          "},
+			&sip2.PatronInformationResponse{
+				PatronStatus:        "              ",
+				InstitutionID:       "x",
+				PatronIdentifier:    "p1",
+				ChargedItemsCount:   1,
+				ChargedItems:        []string{"i1"},
+				HoldItemsCount:      1,
+				HoldItems:           []string{"i3"},
+				PersonalName:        "Hamsun, Knut",
+				EmailAddress:        "knut@example.org",
+				ValidPatron:         boolPtr(true),
+				ValidPatronPassword: boolPtr(true),
+			},
+		},
+		{
+			&sip2.PatronInformationRequest{InstitutionID: "x", PatronIdentifier: "p1", PatronPassword: "0000", TransactionDate: now, Summary: "          "},
+			&sip2.PatronInformationResponse{
+				PatronStatus:        "              ",
+				InstitutionID:       "x",
+				PatronIdentifier:    "p1",
+				ValidPatron:         boolPtr(true),
+				ValidPatronPassword: boolPtr(false),
+				ScreenMessage:       []string{"User Authentication Failed"},
+			},
+		},
+		{
+			&sip2.PatronInformationRequest{InstitutionID: "x", PatronIdentifier: "p9", TransactionDate: now, Summary: "          "},
+			&sip2.PatronInformationResponse{
+				PatronStatus:     "              ",
+				InstitutionID:    "x",
+				PatronIdentifier: "p9",
+				ValidPatron:      boolPtr(false),
+				ScreenMessage:    []string{"Unknown User"},
+			},
+		},
+		{
+			&sip2.ItemInformationRequest{InstitutionID: "x", ItemIdentifier: "i1", TransactionDate: now},
+			&sip2.ItemInformationResponse{
+				CirculationStatus: sip2.CirculationCharged,
+				FeeType:           sip2.FeeOther,
+				HoldQueueLength:   2,
+				HoldPickupDate:    time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC).Local(),
+				ItemIdentifier:    "i1",
+				TitleIdentifier:   "Sult",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		resp := b.Handle(test.req.Message())
+		got, err := resp.Typed()
+		if err != nil {
+			t.Errorf("%v: response %v: %v", test.req, resp, err)
+			continue
+		}
+		clearTransactionDate(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%T => %+v; want %+v", test.req, got, test.want)
+		}
+	}
+}
+
+func TestBridgeUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	b := New(srv.URL, "sc", "lib")
+
+	req := sip2.CheckinRequest{InstitutionID: "x", ItemIdentifier: "i1", TransactionDate: time.Now(), ReturnDate: time.Now()}
+	resp := b.Handle(req.Message())
+	if resp.Field(sip2.FieldOK) != "0" || resp.Field(sip2.FieldScreenMessage) != "Service unavailable" {
+		t.Errorf("checkin with NCIP endpoint down => %v; want not OK with screen message", resp)
+	}
+
+	// Requests which are not translated go to the fallback Handler.
+	resp = b.Handle(sip2.NewMessage(sip2.MsgReqStatus).AddField(
+		sip2.Field{Type: sip2.FieldStatusCode, Value: "0"},
+		sip2.Field{Type: sip2.FieldMaxPrintWidth, Value: "040"},
+		sip2.Field{Type: sip2.FieldProtocolVersion, Value: "2.00"},
+	))
+	if resp.Type() != sip2.MsgRespStatus {
+		t.Errorf("status request => %v; want status response from fallback", resp)
+	}
+}
+
+func TestBridgeDesiredDateDue(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := ncip.DecodeRequest(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, req.(*ncip.CheckOutItem).DesiredDateDue)
+		ncip.EncodeResponse(w, &ncip.CheckOutItemResponse{})
+	}))
+	defer srv.Close()
+	b := New(srv.URL, "sc", "lib")
+
+	due := time.Date(2026, 11, 16, 0, 0, 0, 0, time.Local)
+	for _, noBlock := range []bool{false, true} {
+		req := sip2.CheckoutRequest{NoBlock: noBlock, NbDueDate: due, TransactionDate: due, PatronIdentifier: "p1", ItemIdentifier: "i1"}
+		b.Handle(req.Message())
+	}
+	// The no block due date is only requested for no block checkouts.
+	if want := []string{"", due.Format(time.RFC3339)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DesiredDateDue of checkouts without and with no block: %q; want %q", got, want)
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// clearTransactionDate zeroes the transaction date of a response, which is
+// set to the time it was made.
+func clearTransactionDate(m sip2.TypedMessage) {
+	v := reflect.ValueOf(m).Elem().FieldByName("TransactionDate")
+	if v.IsValid() {
+		v.Set(reflect.ValueOf(time.Time{}))
+	}
+}
@@ -0,0 +1,307 @@
+package sip2
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Balance is the strategy a Proxy uses to choose between upstream servers.
+type Balance int
+
+const (
+	// Failover sends all clients to the first available upstream, in the
+	// order given. The next one is used only if it fails.
+	Failover Balance = iota
+
+	// RoundRobin distributes clients evenly between available upstreams.
+	RoundRobin
+)
+
+// Rewrite replaces the value of a field in requests passing through a Proxy.
+// If From is empty, any value is replaced. Otherwise only the value From is
+// replaced, and the replacement is reversed in responses, so that clients see
+// the value they sent.
+type Rewrite struct {
+	Field fieldType
+	From  string
+	To    string
+}
+
+// Proxy is a Handler which forwards requests to one or more upstream ACS
+// servers. Use it with a Server to run a SIP2 gateway between self-service
+// terminals and the ACS.
+//
+// Each client gets its own upstream connection, which is used for all its
+// requests, since SIP2 is stateful: a Login request is forwarded, and sent
+// again on each new upstream connection, when the Proxy reconnects or fails
+// over to another upstream. If the login is no longer accepted, the
+// upstream is treated as failed.
+//
+// An upstream which fails is not used for new connections until
+// RetryAfter has passed. If no upstream is available, clients get a
+// response which is not OK.
+type Proxy struct {
+	// Balance is the strategy for choosing an upstream. Defaults to Failover.
+	Balance Balance
+
+	// Timeout is the timeout of each request to an upstream.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// RetryAfter is how long to wait before trying an upstream again after
+	// it has failed. Defaults to 30 seconds.
+	RetryAfter time.Duration
+
+	// TLSConfig is used to connect to the upstreams over TLS, if not nil.
+	TLSConfig *tls.Config
+
+	// Logger, if not nil, gets a log record of each transaction, with the
+	// request as sent by the client, with passwords masked.
+	Logger Logger
+
+	mu        sync.Mutex
+	upstreams []*upstream
+	next      int // next upstream to use with RoundRobin
+	rewrites  []Rewrite
+	blocked   map[msgType]bool
+	conns     map[*Session]*proxyConn
+}
+
+var errNoUpstream = errors.New("sip2: no upstream available")
+
+type upstream struct {
+	addr      string
+	downUntil time.Time // guarded by Proxy.mu
+}
+
+// proxyConn is the upstream connection of a client.
+type proxyConn struct {
+	mu     sync.Mutex // serializes requests of the client
+	client *Client
+	up     *upstream
+	login  *Message // forwarded login request, if any
+}
+
+// NewProxy returns a new Proxy forwarding requests to the upstream ACS
+// servers at the given addresses.
+func NewProxy(upstreams ...string) *Proxy {
+	p := &Proxy{
+		Timeout:    30 * time.Second,
+		RetryAfter: 30 * time.Second,
+		blocked:    make(map[msgType]bool),
+		conns:      make(map[*Session]*proxyConn),
+	}
+	for _, addr := range upstreams {
+		p.upstreams = append(p.upstreams, &upstream{addr: addr})
+	}
+	return p
+}
+
+// Block makes the Proxy refuse requests of the given message types. Clients
+// get a response which is not OK, without the request reaching an upstream.
+func (p *Proxy) Block(types ...msgType) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range types {
+		p.blocked[t] = true
+	}
+}
+
+// AddRewrite adds field rewrites, which are applied in the order added.
+func (p *Proxy) AddRewrite(rw ...Rewrite) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rewrites = append(p.rewrites, rw...)
+}
+
+// Handle forwards a request on a connection shared by all clients
+// without a Session. A Server always calls HandleSession instead.
+func (p *Proxy) Handle(req Message) Message {
+	return p.HandleSession(nil, req)
+}
+
+// HandleSession forwards a request on the upstream connection of the client.
+func (p *Proxy) HandleSession(sess *Session, req Message) Message {
+	start := time.Now()
+	p.mu.Lock()
+	blocked := p.blocked[req.typ]
+	p.mu.Unlock()
+	if blocked {
+		resp := notOKResponse(req, "", "Not allowed")
+		p.log(sess, req, resp, "", start, "blocked")
+		return resp
+	}
+
+	up, resp, err := p.forward(sess, p.rewrite(req, false))
+	if err != nil {
+		resp = notOKResponse(req, "", "Service unavailable")
+		p.log(sess, req, resp, up, start, err.Error())
+		return resp
+	}
+	resp = p.rewrite(resp, true)
+	p.log(sess, req, resp, up, start, "")
+	return resp
+}
+
+// CloseSession closes the upstream connection of the client.
+func (p *Proxy) CloseSession(sess *Session) {
+	p.mu.Lock()
+	pc, ok := p.conns[sess]
+	delete(p.conns, sess)
+	p.mu.Unlock()
+	if ok {
+		pc.mu.Lock()
+		if pc.client != nil {
+			pc.client.Close()
+		}
+		pc.mu.Unlock()
+	}
+}
+
+// Close closes all upstream connections.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	sessions := make([]*Session, 0, len(p.conns))
+	for sess := range p.conns {
+		sessions = append(sessions, sess)
+	}
+	p.mu.Unlock()
+	for _, sess := range sessions {
+		p.CloseSession(sess)
+	}
+	return nil
+}
+
+// rewrite returns a copy of the message with the rewrites applied, or
+// reversed if reverse is true.
+func (p *Proxy) rewrite(m Message, reverse bool) Message {
+	p.mu.Lock()
+	rewrites := p.rewrites
+	p.mu.Unlock()
+	if len(rewrites) == 0 {
+		return m
+	}
+	m = m.clone()
+	for _, rw := range rewrites {
+		v, ok := m.fields[rw.Field]
+		if !ok {
+			continue
+		}
+		switch {
+		case reverse && rw.From != "" && v == rw.To:
+			m.fields[rw.Field] = rw.From
+		case !reverse && (rw.From == "" || v == rw.From):
+			m.fields[rw.Field] = rw.To
+		}
+	}
+	return m
+}
+
+// forward sends the request to the client's upstream, failing over to the
+// other upstreams if it fails. It returns the address of the upstream which
+// was tried last.
+func (p *Proxy) forward(sess *Session, req Message) (string, Message, error) {
+	p.mu.Lock()
+	pc, ok := p.conns[sess]
+	if !ok {
+		pc = &proxyConn{}
+		p.conns[sess] = pc
+	}
+	p.mu.Unlock()
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	var addr string
+	err := errNoUpstream
+	for attempt := 0; attempt < len(p.upstreams); attempt++ {
+		if pc.client == nil {
+			up := p.pick()
+			if up == nil {
+				break
+			}
+			pc.up = up
+			pc.client = NewClient(up.addr, MessageFactory{})
+			pc.client.Timeout = p.Timeout
+			pc.client.TLSConfig = p.TLSConfig
+			if req.typ != MsgReqLogin {
+				pc.client.setLogin(pc.login)
+			}
+		}
+		addr = pc.up.addr
+
+		var resp Message
+		resp, err = pc.client.Send(req)
+		if err == nil || resp.typ != MsgUnknown {
+			// The upstream responded; pass the response on as is.
+			if req.typ == MsgReqLogin && resp.Field(FieldOK) == "1" {
+				pc.login = &req
+				pc.client.setLogin(pc.login)
+			}
+			return addr, resp, nil
+		}
+		p.fail(pc)
+	}
+	return addr, Message{}, err
+}
+
+// pick returns the upstream to use for a new connection, or nil if all are down.
+func (p *Proxy) pick() *upstream {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.upstreams)
+	if n == 0 {
+		return nil
+	}
+	start := 0
+	if p.Balance == RoundRobin {
+		start = p.next
+		p.next = (p.next + 1) % n
+	}
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		if up := p.upstreams[(start+i)%n]; !now.Before(up.downUntil) {
+			return up
+		}
+	}
+	return nil
+}
+
+// fail marks the upstream of the connection as down, and closes the connection.
+func (p *Proxy) fail(pc *proxyConn) {
+	p.mu.Lock()
+	pc.up.downUntil = time.Now().Add(p.RetryAfter)
+	p.mu.Unlock()
+	pc.client.Close()
+	pc.client = nil
+}
+
+func (p *Proxy) log(sess *Session, req, resp Message, upstream string, start time.Time, errMsg string) {
+	if p.Logger == nil {
+		return
+	}
+	args := []any{
+		"request", req.typ.String(),
+		"response", resp.typ.String(),
+		"upstream", upstream,
+		"duration", time.Since(start),
+		"message", mask(req),
+	}
+	if sess != nil {
+		args = append(args,
+			"client", sess.RemoteAddr().String(),
+			"user", sess.User(),
+		)
+	}
+	if v, ok := resp.FieldOK(FieldOK); ok {
+		args = append(args, "ok", v)
+	}
+	if errMsg != "" {
+		args = append(args, "error", errMsg)
+		p.Logger.Warn("sip2 transaction failed", args...)
+		return
+	}
+	p.Logger.Info("sip2 transaction", args...)
+}
@@ -0,0 +1,206 @@
+package sip2
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingRouter returns a Router answering Login and Checkin requests,
+// counting the requests it gets.
+func countingRouter(name string, counts map[string]int, mu *sync.Mutex) *Router {
+	rt := NewRouter()
+	count := func(t msgType) {
+		mu.Lock()
+		counts[name+" "+t.String()]++
+		mu.Unlock()
+	}
+	rt.RouteFunc(MsgReqLogin, func(req Message) Message {
+		count(req.Type())
+		return loginResponse(req.Field(FieldLoginUserID) == "user")
+	})
+	rt.RouteFunc(MsgReqCheckin, func(req Message) Message {
+		count(req.Type())
+		return CheckinResponse{
+			OK:                req.Field(FieldInstitutionID) == "backend",
+			MagneticMedia:     Unknown,
+			InstitutionID:     req.Field(FieldInstitutionID),
+			ItemIdentifier:    req.Field(FieldItemIdentifier),
+			PermanentLocation: name,
+		}.Message()
+	})
+	return rt
+}
+
+func TestProxy(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+
+	var upstreams []*Server
+	var addrs []string
+	for _, name := range []string{"a", "b"} {
+		s, err := NewServer(countingRouter(name, counts, &mu), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go s.Run()
+		defer s.Close()
+		upstreams = append(upstreams, s)
+		addrs = append(addrs, s.Addr().String())
+	}
+
+	var logs bytes.Buffer
+	p := NewProxy(addrs...)
+	p.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	p.Block(MsgReqFeePaid)
+	p.AddRewrite(Rewrite{Field: FieldInstitutionID, From: "kiosk", To: "backend"})
+	defer p.Close()
+
+	ps, err := NewServer(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ps.Run()
+	defer ps.Close()
+
+	mf := NewMessageFactory(
+		Field{Type: FieldInstitutionID, Value: "kiosk"},
+		Field{Type: FieldTerminalPassword, Value: ""},
+	)
+	c := NewClient(ps.Addr().String(), mf)
+	defer c.Close()
+	if err := c.Login("user", "pass", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Checkin("1234", "here")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldOK) != "1" || resp.Field(FieldInstitutionID) != "kiosk" || resp.Field(FieldPermanentLocation) != "a" {
+		t.Errorf("checkin through proxy => %v; want OK from upstream a with institution rewritten back", resp)
+	}
+
+	resp, err = c.FeePaid("p1", "01", "00", "NOK", "10.00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldPaymentAccepted) != "N" {
+		t.Errorf("blocked fee paid => %v; want payment not accepted", resp)
+	}
+
+	// Fail over to upstream b, which must get the login too.
+	upstreams[0].Close()
+	resp, err = c.Checkin("1234", "here")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Field(FieldOK) != "1" || resp.Field(FieldPermanentLocation) != "b" {
+		t.Errorf("checkin after failover => %v; want OK from upstream b", resp)
+	}
+
+	mu.Lock()
+	want := map[string]int{
+		"a MsgReqLogin":   1,
+		"a MsgReqCheckin": 1,
+		"b MsgReqLogin":   1,
+		"b MsgReqCheckin": 1,
+	}
+	for k, n := range want {
+		if counts[k] != n {
+			t.Errorf("%s: got %d requests; want %d", k, counts[k], n)
+		}
+	}
+	mu.Unlock()
+
+	for _, want := range []string{
+		"request=MsgReqCheckin response=MsgRespCheckin upstream=" + addrs[0],
+		"request=MsgReqFeePaid response=MsgRespFeePaid upstream=\"\"",
+		"error=blocked",
+		"user=user",
+		"CO****|",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log does not contain %q:\n%s", want, logs.String())
+		}
+	}
+	if strings.Contains(logs.String(), "COpass") {
+		t.Errorf("log contains the login password:\n%s", logs.String())
+	}
+}
+
+func TestProxyReconnect(t *testing.T) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	up, err := NewServer(countingRouter("a", counts, &mu), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The upstream closes idle connections, and only answers requests on
+	// connections which have logged in.
+	up.IdleTimeout = 20 * time.Millisecond
+	up.UseAuth(AuthenticatorFunc(func(user, password, location string) bool {
+		mu.Lock()
+		counts["a MsgReqLogin"]++
+		mu.Unlock()
+		return user == "user"
+	}))
+	go up.Run()
+	defer up.Close()
+
+	p := NewProxy(up.Addr().String())
+	defer p.Close()
+	login := NewMessage(MsgReqLogin).AddField(
+		Field{Type: FieldUIDAlgorithm, Value: "0"},
+		Field{Type: FieldPWDAlgorithm, Value: "0"},
+		Field{Type: FieldLoginUserID, Value: "user"},
+		Field{Type: FieldLoginPassword, Value: "pass"},
+	)
+	if resp := p.Handle(login); resp.Field(FieldOK) != "1" {
+		t.Fatalf("login through proxy => %v; want OK", resp)
+	}
+
+	checkin := testMF.NewMessage(MsgReqCheckin).AddField(
+		Field{Type: FieldInstitutionID, Value: "backend"},
+		Field{Type: FieldItemIdentifier, Value: "1234"},
+		Field{Type: FieldCurrentLocation, Value: "here"},
+	)
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			// Let the upstream close the connection.
+			time.Sleep(50 * time.Millisecond)
+		}
+		if resp := p.Handle(checkin); resp.Type() != MsgRespCheckin || resp.Field(FieldOK) != "1" {
+			t.Fatalf("checkin %d through proxy => %v; want OK checkin response", i+1, resp)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counts["a MsgReqLogin"] != 2 || counts["a MsgReqCheckin"] != 2 {
+		t.Errorf("upstream got %d logins and %d checkins; want 2 of each", counts["a MsgReqLogin"], counts["a MsgReqCheckin"])
+	}
+}
+
+func TestProxyUnavailable(t *testing.T) {
+	p := NewProxy("127.0.0.1:1")
+	resp := p.Handle(testMF.NewMessage(MsgReqStatus))
+	if resp.Type() != MsgRespStatus || resp.Field(FieldOnLineStatus) != "N" {
+		t.Errorf("status without upstream => %v; want ACS offline", resp)
+	}
+}
+
+func TestProxyRoundRobin(t *testing.T) {
+	p := NewProxy("a", "b", "c")
+	p.Balance = RoundRobin
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, p.pick().addr)
+	}
+	if strings.Join(got, ",") != "a,b,c,a" {
+		t.Errorf("round robin picked %v; want [a b c a]", got)
+	}
+}
@@ -0,0 +1,199 @@
+package sip2
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a message sent between a client (SC) and an ACS, as recorded
+// by a Recorder.
+type Record struct {
+	Time    time.Time
+	Client  string // network address of the client
+	Request bool   // true if sent by the client, false if sent by the ACS
+	Data    []byte // the message as sent on the wire
+}
+
+// Message returns the decoded message of the Record.
+func (r Record) Message() (Message, error) {
+	return Decode(r.Data)
+}
+
+// String formats the Record as a line in the recording format:
+//
+//	<time> <client> -> <quoted message>
+//	<time> <client> <- <quoted message>
+//
+// where the time is in RFC3339 format with nanoseconds, and the message is
+// quoted as a Go string, so that control characters and bytes which are not
+// valid UTF-8 are preserved.
+func (r Record) String() string {
+	dir := "<-"
+	if r.Request {
+		dir = "->"
+	}
+	return fmt.Sprintf("%s %s %s %q", r.Time.Format(time.RFC3339Nano), r.Client, dir, r.Data)
+}
+
+// Recorder records SIP2 traffic to a writer, one Record per line.
+// It is safe for concurrent use.
+type Recorder struct {
+	// Passwords are recorded in plain text if RecordPasswords is true.
+	// Otherwise, the default, patron and login passwords (AD and CO) are
+	// replaced by asterisks, and the checksum of messages with passwords
+	// is recomputed, so that they can still be replayed.
+	RecordPasswords bool
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecorder returns a new Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Record records a message sent by or to the given client.
+func (rec *Recorder) Record(client string, request bool, b []byte) error {
+	r := Record{
+		Time:    time.Now(),
+		Client:  client,
+		Request: request,
+		Data:    b,
+	}
+	if !rec.RecordPasswords {
+		r.Data = maskRecord(b)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	_, err := io.WriteString(rec.w, r.String()+"\n")
+	return err
+}
+
+// maskRecord returns the message with passwords replaced by asterisks, and
+// the checksum recomputed if there is one.
+func maskRecord(b []byte) []byte {
+	masked := maskRaw(b)
+	if masked == strings.TrimSuffix(string(b), "\r") {
+		return b
+	}
+	if l := len(masked); l >= 6 && masked[l-6:l-4] == "AZ" {
+		masked = masked[:l-4] + checksum([]byte(masked[:l-4]))
+	}
+	if bytes.HasSuffix(b, []byte("\r")) {
+		masked += "\r"
+	}
+	return []byte(masked)
+}
+
+// ReadRecords reads a recording made by a Recorder.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var res []Record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		parts := strings.SplitN(sc.Text(), " ", 4)
+		if len(parts) != 4 || (parts[2] != "->" && parts[2] != "<-") {
+			return nil, fmt.Errorf("sip2: malformed record on line %d", n)
+		}
+		t, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("sip2: malformed record on line %d: %v", n, err)
+		}
+		data, err := strconv.Unquote(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("sip2: malformed record on line %d: %v", n, err)
+		}
+		res = append(res, Record{
+			Time:    t,
+			Client:  parts[1],
+			Request: parts[2] == "->",
+			Data:    []byte(data),
+		})
+	}
+	return res, sc.Err()
+}
+
+// Sessions groups Records by client, keeping the order of the Records of
+// each client. The clients are ordered by the time of their first Record.
+func Sessions(records []Record) [][]Record {
+	var res [][]Record
+	idx := make(map[string]int)
+	for _, r := range records {
+		i, ok := idx[r.Client]
+		if !ok {
+			i = len(res)
+			idx[r.Client] = i
+			res = append(res, nil)
+		}
+		res[i] = append(res[i], r)
+	}
+	return res
+}
+
+// Diff compares two messages, ignoring the given fields, and returns the
+// differences, one string per field, or nil if the messages are equal.
+// It is typically used to compare a recorded response with the response
+// to the same request replayed at a later time.
+func Diff(want, got Message, ignore ...fieldType) []string {
+	if want.typ != got.typ {
+		return []string{fmt.Sprintf("message type: %v != %v", want.typ, got.typ)}
+	}
+	skip := make(map[fieldType]bool, len(ignore))
+	for _, f := range ignore {
+		skip[f] = true
+	}
+
+	fields := make(map[fieldType]bool)
+	for f := range want.fields {
+		fields[f] = true
+	}
+	for f := range got.fields {
+		fields[f] = true
+	}
+	for f := range want.repeateableFields {
+		fields[f] = true
+	}
+	for f := range got.repeateableFields {
+		fields[f] = true
+	}
+	sorted := make([]fieldType, 0, len(fields))
+	for f := range fields {
+		if !skip[f] {
+			sorted = append(sorted, f)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var res []string
+	for _, f := range sorted {
+		if isRepeatable(f) {
+			w, g := want.Fields(f), got.Fields(f)
+			if strings.Join(w, "|") != strings.Join(g, "|") {
+				res = append(res, fmt.Sprintf("%v: %q != %q", f, w, g))
+			}
+			continue
+		}
+		w, wok := want.FieldOK(f)
+		g, gok := got.FieldOK(f)
+		switch {
+		case wok && !gok:
+			res = append(res, fmt.Sprintf("%v: %q != <missing>", f, w))
+		case !wok && gok:
+			res = append(res, fmt.Sprintf("%v: <missing> != %q", f, g))
+		case w != g:
+			res = append(res, fmt.Sprintf("%v: %q != %q", f, w, g))
+		}
+	}
+	return res
+}
@@ -0,0 +1,158 @@
+package sip2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	rt := NewRouter()
+	s, err := NewServer(rt, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Recorder = NewRecorder(&buf)
+	go s.Run()
+
+	c := NewClient(s.Addr().String(), testMF)
+	if err := c.Login("user", "pass", "here"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	s.Close()
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("recorded %d messages; want 4:\n%s", len(records), buf.String())
+	}
+	var types []msgType
+	for _, r := range records {
+		m, err := r.Message()
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, m.Type())
+	}
+	want := []msgType{MsgReqLogin, MsgRespLogin, MsgReqStatus, MsgRespStatus}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("recorded %v; want %v", types, want)
+	}
+	if !records[0].Request || records[1].Request {
+		t.Errorf("requests not marked as such: %v", records[:2])
+	}
+	if got := string(records[3].Data); got != resp.String() {
+		t.Errorf("recorded response %q; want %q", got, resp.String())
+	}
+	if sessions := Sessions(records); len(sessions) != 1 || len(sessions[0]) != 4 {
+		t.Errorf("Sessions() => %v; want 1 session with 4 records", sessions)
+	}
+}
+
+func TestRecorderPasswords(t *testing.T) {
+	login := NewMessage(MsgReqLogin).AddField(
+		Field{Type: FieldUIDAlgorithm, Value: "0"},
+		Field{Type: FieldPWDAlgorithm, Value: "0"},
+		Field{Type: FieldLoginUserID, Value: "user"},
+		Field{Type: FieldLoginPassword, Value: "secret"},
+		Field{Type: FieldSequenceNumber, Value: "1"},
+		Field{Type: FieldChecksum},
+	)
+	var b bytes.Buffer
+	if err := login.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plain := range []bool{false, true} {
+		var buf bytes.Buffer
+		rec := NewRecorder(&buf)
+		rec.RecordPasswords = plain
+		if err := rec.Record("127.0.0.1:1234", true, b.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		records, err := ReadRecords(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := records[0].Message()
+		if err != nil {
+			t.Fatalf("recorded message with RecordPasswords=%v cannot be decoded: %v", plain, err)
+		}
+		want := "****"
+		if plain {
+			want = "secret"
+		}
+		if got := m.Field(FieldLoginPassword); got != want {
+			t.Errorf("recorded password with RecordPasswords=%v: %q; want %q", plain, got, want)
+		}
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	in := `2026-10-19T12:00:00.5Z 10.0.0.1:5000 -> "9300CNuser|COpass|\r"
+2026-10-19T12:00:01Z 10.0.0.2:5000 -> "9300CNuser|COpass|\r"
+2026-10-19T12:00:01.2Z 10.0.0.1:5000 <- "941\r"
+`
+	records, err := ReadRecords(bytes.NewBufferString(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	for _, r := range records {
+		out.WriteString(r.String() + "\n")
+	}
+	if out.String() != in {
+		t.Errorf("records formatted as\n%s; want\n%s", out.String(), in)
+	}
+	sessions := Sessions(records)
+	if len(sessions) != 2 || len(sessions[0]) != 2 || sessions[1][0].Client != "10.0.0.2:5000" {
+		t.Errorf("Sessions() => %v", sessions)
+	}
+
+	for _, bad := range []string{
+		"2026-10-19T12:00:00Z 10.0.0.1:5000 => \"941\r\"\n",
+		"yesterday 10.0.0.1:5000 -> \"941\r\"\n",
+		"2026-10-19T12:00:00Z 10.0.0.1:5000 -> 941\n",
+	} {
+		if _, err := ReadRecords(bytes.NewBufferString(bad)); err == nil {
+			t.Errorf("ReadRecords(%q) => nil error; want error", bad)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := NewMessage(MsgRespCheckin).AddField(
+		Field{Type: FieldOK, Value: "1"},
+		Field{Type: FieldTransactionDate, Value: "20160822    153450"},
+		Field{Type: FieldScreenMessage, Value: "Hi"},
+	)
+	b := NewMessage(MsgRespCheckin).AddField(
+		Field{Type: FieldOK, Value: "0"},
+		Field{Type: FieldTransactionDate, Value: "20160823    153450"},
+		Field{Type: FieldSortBin, Value: "3"},
+	)
+
+	got := Diff(a, b, FieldTransactionDate)
+	want := []string{
+		`FieldOK: "1" != "0"`,
+		`FieldScreenMessage: ["Hi"] != []`,
+		`FieldSortBin: <missing> != "3"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =>\n%q; want\n%q", got, want)
+	}
+	if d := Diff(a, a); d != nil {
+		t.Errorf("Diff(a, a) => %q; want nil", d)
+	}
+	if d := Diff(a, NewMessage(MsgRespLogin)); len(d) != 1 {
+		t.Errorf("Diff of different types => %q; want 1 difference", d)
+	}
+}
@@ -0,0 +1,284 @@
+package sip2
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as Handlers.
+type HandlerFunc func(request Message) (response Message)
+
+// Handle calls f(request).
+func (f HandlerFunc) Handle(request Message) Message {
+	return f(request)
+}
+
+// SessionHandlerFunc is an adapter to allow the use of ordinary functions as
+// SessionHandlers.
+type SessionHandlerFunc func(sess *Session, request Message) (response Message)
+
+// Handle calls f(nil, request).
+func (f SessionHandlerFunc) Handle(request Message) Message {
+	return f(nil, request)
+}
+
+// HandleSession calls f(sess, request).
+func (f SessionHandlerFunc) HandleSession(sess *Session, request Message) Message {
+	return f(sess, request)
+}
+
+// Middleware wraps a Handler, to add behaviour like logging or validation
+// before or after the wrapped Handler is called. Middleware which needs the
+// Session of the request returns a SessionHandler, ex: a SessionHandlerFunc;
+// the Session is passed on by the wrapped Handler.
+type Middleware func(Handler) Handler
+
+// Router is a Handler which dispatches requests to the Handler registered for
+// the message type of the request. It is a SessionHandler, and gives the
+// Session to registered Handlers and Middleware which are SessionHandlers.
+//
+// Requests without a registered Handler get a default response:
+//
+//   - SC Status requests get an ACS Status response, advertising the message
+//     types with a registered Handler as supported.
+//   - Login requests are accepted. Use Server.UseAuth to authenticate clients.
+//   - Other requests get a response of the corresponding type, which is not OK.
+//     Requests without a corresponding response type get a Request SC Resend
+//     response.
+type Router struct {
+	// InstitutionID is used in default responses, if not given in the request.
+	InstitutionID string
+
+	// TimeoutPeriod and RetriesAllowed are reported in the default ACS Status
+	// response. Zero values are reported as 999, meaning unknown.
+	TimeoutPeriod  int
+	RetriesAllowed int
+
+	mu         sync.RWMutex
+	handlers   map[msgType]Handler
+	middleware []Middleware
+}
+
+// NewRouter returns a new Router without any registered Handlers.
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[msgType]Handler),
+	}
+}
+
+// Route registers the Handler for requests of the given message type,
+// replacing any Handler previously registered for it.
+func (rt *Router) Route(t msgType, h Handler) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.handlers[t] = h
+}
+
+// RouteFunc registers the handler function for requests of the given message type.
+func (rt *Router) RouteFunc(t msgType, f func(request Message) Message) {
+	rt.Route(t, HandlerFunc(f))
+}
+
+// RouteSessionFunc registers the handler function, which is given the Session
+// of the client, for requests of the given message type.
+func (rt *Router) RouteSessionFunc(t msgType, f func(sess *Session, request Message) Message) {
+	rt.Route(t, SessionHandlerFunc(f))
+}
+
+// Use adds middleware wrapping all requests handled by the Router, including
+// those given a default response. Middleware is applied in the order added,
+// so the first one added sees the request first.
+func (rt *Router) Use(mw ...Middleware) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.middleware = append(rt.middleware, mw...)
+}
+
+// Handle dispatches the request without a Session. A Server always calls
+// HandleSession instead.
+func (rt *Router) Handle(request Message) Message {
+	return rt.HandleSession(nil, request)
+}
+
+// HandleSession dispatches the request to the Handler registered for its
+// type, through any middleware.
+func (rt *Router) HandleSession(sess *Session, request Message) Message {
+	rt.mu.RLock()
+	var h Handler = HandlerFunc(func(req Message) Message {
+		return rt.route(sess, req)
+	})
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		h = withSession(rt.middleware[i](h), sess)
+	}
+	rt.mu.RUnlock()
+	return h.Handle(request)
+}
+
+// withSession returns a Handler calling the HandleSession method of h with
+// the Session, if h is a SessionHandler, or else h.
+func withSession(h Handler, sess *Session) Handler {
+	sh, ok := h.(SessionHandler)
+	if !ok {
+		return h
+	}
+	return HandlerFunc(func(req Message) Message {
+		return sh.HandleSession(sess, req)
+	})
+}
+
+func (rt *Router) route(sess *Session, req Message) Message {
+	rt.mu.RLock()
+	h, ok := rt.handlers[req.typ]
+	rt.mu.RUnlock()
+	if ok {
+		return withSession(h, sess).Handle(req)
+	}
+
+	switch req.typ {
+	case MsgReqStatus:
+		return rt.status()
+	case MsgReqLogin:
+		return loginResponse(true)
+	}
+	return rt.unsupported(req)
+}
+
+func (rt *Router) routed(t msgType) bool {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	_, ok := rt.handlers[t]
+	return ok
+}
+
+// supportedMessages are the message types in the order of the flags of the
+// Supported Messages field (BX).
+var supportedMessages = []msgType{
+	MsgReqPatronStatus,
+	MsgReqCheckout,
+	MsgReqCheckin,
+	MsgReqBlockPatron,
+	MsgReqStatus,
+	MsgReqResend,
+	MsgReqLogin,
+	MsgReqPatronInformation,
+	MsgReqEndPatronSession,
+	MsgReqFeePaid,
+	MsgReqItemInformation,
+	MsgReqItemStatusUpdate,
+	MsgReqPatronEnable,
+	MsgReqHold,
+	MsgReqRenew,
+	MsgReqRenewAll,
+}
+
+// status returns the default ACS Status response.
+func (rt *Router) status() Message {
+	var bx strings.Builder
+	for _, t := range supportedMessages {
+		// SC Status and Login are always answered by the Router, and
+		// resends are handled by the Server.
+		if t == MsgReqStatus || t == MsgReqResend || t == MsgReqLogin || rt.routed(t) {
+			bx.WriteByte('Y')
+		} else {
+			bx.WriteByte('N')
+		}
+	}
+
+	resp := StatusResponse{
+		OnLineStatus:      true,
+		CheckinOK:         rt.routed(MsgReqCheckin),
+		CheckoutOK:        rt.routed(MsgReqCheckout),
+		RenewalPolicy:     rt.routed(MsgReqRenew),
+		StatusUpdateOK:    rt.routed(MsgReqItemStatusUpdate),
+		TimeoutPeriod:     rt.TimeoutPeriod,
+		RetriesAllowed:    rt.RetriesAllowed,
+		DateTimeSync:      time.Now(),
+		ProtocolVersion:   "2.00",
+		InstitutionID:     rt.InstitutionID,
+		SupportedMessages: bx.String(),
+	}
+	if resp.TimeoutPeriod == 0 {
+		resp.TimeoutPeriod = 999
+	}
+	if resp.RetriesAllowed == 0 {
+		resp.RetriesAllowed = 999
+	}
+	return resp.Message()
+}
+
+// unsupported returns a response, which is not OK, to a request for which
+// there is no Handler.
+func (rt *Router) unsupported(req Message) Message {
+	return notOKResponse(req, rt.InstitutionID, "Not supported")
+}
+
+// notOKResponse returns a response to the request which is not OK, with the
+// given screen message. Required fields are copied from the request when
+// present, otherwise they are given a negative or blank value. The
+// institution ID is used if the request has none. Requests without a
+// corresponding response type, such as SC Resend, get a Request SC Resend
+// response.
+func notOKResponse(req Message, institutionID, screenMsg string) Message {
+	t, ok := responseTypes[req.typ]
+	if !ok {
+		return NewMessage(MsgRespResend)
+	}
+	now := time.Now().Format(DateLayout)
+	resp := NewMessage(t)
+	for _, f := range msgDefinitions[t].RequiredFixed {
+		resp.AddField(Field{Type: f, Value: negativeValue(f, now)})
+	}
+	for _, f := range msgDefinitions[t].RequiredVar {
+		resp.AddField(Field{Type: f, Value: req.Field(f)})
+	}
+	if v, ok := req.FieldOK(FieldInstitutionID); !ok || v == "" {
+		resp.AddField(Field{Type: FieldInstitutionID, Value: institutionID})
+	}
+	if isOptional(t, FieldScreenMessage) {
+		resp.AddField(Field{Type: FieldScreenMessage, Value: screenMsg})
+	}
+	return resp
+}
+
+// negativeValue returns the value of a required fixed-length field in a
+// response which is not OK: flags are N or 0, numbers are zero, timestamps
+// are the given time, and any other field is blank.
+func negativeValue(f fieldType, now string) string {
+	switch f {
+	case FieldOK, FieldItemPropertiesOK:
+		return "0"
+	case FieldMagneticMedia, FieldDesentisize:
+		return "U"
+	case FieldCirulationStatus, FieldFeeType:
+		return "01" // other
+	case FieldProtocolVersion:
+		return "2.00"
+	}
+	switch fieldValdiation[f] {
+	case rxpYesNo:
+		return "N"
+	case rxpTimestamp:
+		return now
+	case rxpDigits, rxpDigitsOrBlank:
+		return strings.Repeat("0", fixedFieldLengths[f])
+	}
+	return strings.Repeat(" ", fixedFieldLengths[f])
+}
+
+// LogRequests returns Middleware which logs each request, with passwords
+// masked, the type of the response, and the time taken by the Handler, to
+// the given Logger.
+func LogRequests(l Logger) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(req Message) Message {
+			start := time.Now()
+			resp := next.Handle(req)
+			l.Info("sip2 request handled",
+				"request", mask(req),
+				"response", resp.typ.String(),
+				"duration", time.Since(start))
+			return resp
+		})
+	}
+}
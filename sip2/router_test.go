@@ -0,0 +1,160 @@
+package sip2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	rt := NewRouter()
+	rt.InstitutionID = "Acme co."
+	rt.RouteFunc(MsgReqCheckout, func(req Message) Message {
+		return CheckoutResponse{
+			OK:               true,
+			MagneticMedia:    No,
+			Desensitize:      Yes,
+			InstitutionID:    req.Field(FieldInstitutionID),
+			PatronIdentifier: req.Field(FieldPatronIdentifier),
+			ItemIdentifier:   req.Field(FieldItemIdentifier),
+		}.Message()
+	})
+	rt.RouteFunc(MsgReqCheckin, func(req Message) Message {
+		return NewMessage(MsgRespCheckin).AddField(Field{Type: FieldOK, Value: "1"})
+	})
+
+	var calls []string
+	for _, name := range []string{"first", "second"} {
+		name := name
+		rt.Use(func(next Handler) Handler {
+			return HandlerFunc(func(req Message) Message {
+				calls = append(calls, name)
+				return next.Handle(req)
+			})
+		})
+	}
+
+	tests := []struct {
+		req  Message
+		want []Field
+	}{
+		{
+			testMF.NewMessage(MsgReqCheckout).AddField(
+				Field{Type: FieldPatronIdentifier, Value: "p1"},
+				Field{Type: FieldItemIdentifier, Value: "i1"}),
+			[]Field{
+				{Type: FieldOK, Value: "1"},
+				{Type: FieldPatronIdentifier, Value: "p1"},
+				{Type: FieldItemIdentifier, Value: "i1"},
+			},
+		},
+		{
+			testMF.NewMessage(MsgReqStatus),
+			[]Field{
+				{Type: FieldOnLineStatus, Value: "Y"},
+				{Type: FieldCheckinOK, Value: "Y"},
+				{Type: FieldCheckoutOK, Value: "Y"},
+				{Type: FieldRenewalPolicy, Value: "N"},
+				{Type: FieldTimeoutPeriod, Value: "999"},
+				{Type: FieldInstitutionID, Value: "Acme co."},
+				{Type: FieldSupportedMessages, Value: "NYYNYYYNNNNNNNNN"},
+			},
+		},
+		{
+			NewMessage(MsgReqLogin),
+			[]Field{{Type: FieldOK, Value: "1"}},
+		},
+		{
+			testMF.NewMessage(MsgReqRenew).AddField(
+				Field{Type: FieldPatronIdentifier, Value: "p1"},
+				Field{Type: FieldItemIdentifier, Value: "i1"}),
+			[]Field{
+				{Type: FieldOK, Value: "0"},
+				{Type: FieldRenewalOK, Value: "N"},
+				{Type: FieldMagneticMedia, Value: "U"},
+				{Type: FieldInstitutionID, Value: "Acme co."},
+				{Type: FieldPatronIdentifier, Value: "p1"},
+				{Type: FieldItemIdentifier, Value: "i1"},
+				{Type: FieldTitleIdentifier, Value: ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls = nil
+		resp := rt.Handle(test.req)
+		if want := responseTypes[test.req.Type()]; resp.Type() != want {
+			t.Errorf("response to %v is %v; want %v", test.req.Type(), resp.Type(), want)
+			continue
+		}
+		for _, f := range test.want {
+			if got := resp.Field(f.Type); got != f.Value {
+				t.Errorf("response to %v: %v = %q; want %q", test.req.Type(), f.Type, got, f.Value)
+			}
+		}
+		if errs := resp.Validate(); len(errs) > 0 {
+			t.Errorf("response to %v is not valid: %v", test.req.Type(), errs)
+		}
+		if strings.Join(calls, ",") != "first,second" {
+			t.Errorf("middleware called in order %v; want [first second]", calls)
+		}
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	var l testLogger
+	rt := NewRouter()
+	rt.Use(LogRequests(&l))
+	rt.Handle(NewMessage(MsgReqLogin).AddField(
+		Field{Type: FieldUIDAlgorithm, Value: "0"},
+		Field{Type: FieldPWDAlgorithm, Value: "0"},
+		Field{Type: FieldLoginUserID, Value: "user"},
+		Field{Type: FieldLoginPassword, Value: "secret"},
+	))
+	if want := "INFOsip2 request handledrequest9300CNuser|CO****|responseMsgRespLoginduration"; !strings.HasPrefix(l.String(), want) {
+		t.Errorf("LogRequests logged %q; want prefix %q", l.String(), want)
+	}
+}
+
+func TestRouterSession(t *testing.T) {
+	sess := &Session{}
+	rt := NewRouter()
+	var got []*Session
+	rt.RouteSessionFunc(MsgReqCheckin, func(s *Session, req Message) Message {
+		got = append(got, s)
+		return NewMessage(MsgRespCheckin).AddField(Field{Type: FieldOK, Value: "1"})
+	})
+	rt.Use(func(next Handler) Handler {
+		return SessionHandlerFunc(func(s *Session, req Message) Message {
+			got = append(got, s)
+			return next.Handle(req)
+		})
+	})
+	rt.Use(func(next Handler) Handler {
+		// Middleware which is not a SessionHandler passes on the Session.
+		return HandlerFunc(next.Handle)
+	})
+
+	rt.HandleSession(sess, NewMessage(MsgReqCheckin))
+	if len(got) != 2 || got[0] != sess || got[1] != sess {
+		t.Errorf("Session given to middleware and handler: %v; want [%p %p]", got, sess, sess)
+	}
+
+	got = nil
+	rt.Handle(NewMessage(MsgReqCheckin))
+	if len(got) != 2 || got[0] != nil || got[1] != nil {
+		t.Errorf("Session given to middleware and handler without Session: %v; want [nil nil]", got)
+	}
+}
+
+func TestRouterWithoutResponseType(t *testing.T) {
+	rt := NewRouter()
+	resp := rt.Handle(NewMessage(MsgReqResend))
+	if resp.Type() != MsgRespResend {
+		t.Errorf("response to %v is %v; want %v", MsgReqResend, resp.Type(), MsgRespResend)
+	}
+	var b bytes.Buffer
+	if err := resp.Encode(&b); err != nil {
+		t.Errorf("response to %v cannot be encoded: %v", MsgReqResend, err)
+	}
+}
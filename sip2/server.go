@@ -2,11 +2,18 @@ package sip2
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -15,13 +22,6 @@ type Handler interface {
 	Handle(request Message) (response Message)
 }
 
-type Client struct {
-	conn          net.Conn
-	authenticated bool
-	lastMessage   Message
-	onlineSince   time.Time
-}
-
 // Server is a SIP2 server, listening for and accepting TCP-connections
 // from clients. The transaction logic must be supplied by an implementation
 // of the Handler interface.
@@ -33,90 +33,536 @@ type Server struct {
 	// When useAuth is true, clients must authenticate with username and password
 	// before being allowed to interact any further with the server.
 	useAuth bool
+	auth    Authenticator
+
+	mu       sync.Mutex
+	sessions map[*Session]bool
+
+	// Validation sets how incoming and outgoing SIP messages are validated
+	// according to the SIP2 specification. Defaults to ValidateOff.
+	Validation Validation
 
-	// When validateMsg is true, incoming and outgoing SIP messages are validated
-	// according to the SIP2 specification, with any violation logged.
-	validateMsg bool
+	// InstitutionID is used in responses rejecting invalid requests,
+	// when the request has none.
+	InstitutionID string
 
-	// Log all incoming requests and outgoing responses.
+	violations map[string]int // protocol violations by client IP, guarded by mu
+
+	// Log all incoming requests and outgoing responses. Passwords are
+	// masked with asterisks.
 	Log bool
+
+	// Logger receives the log records of the Server. Defaults to a Logger
+	// writing to the standard logger of package log.
+	Logger Logger
+
+	// Metrics, if not nil, collects counters and latency histograms of the
+	// requests handled.
+	Metrics *Metrics
+
+	// When ErrorDetection is true, all responses are sent with a checksum.
+	// Regardless of this setting, responses to requests with a checksum are
+	// sent with a checksum and the sequence number of the request.
+	ErrorDetection bool
+
+	// Charset is the character encoding of messages to and from clients.
+	// Defaults to UTF8.
+	Charset Charset
+
+	// ClientCharsets overrides Charset for the clients with the given IPs,
+	// for deployments where kiosks differ in their character encoding.
+	ClientCharsets map[string]Charset
+
+	// Recorder, if not nil, records all messages to and from clients.
+	Recorder *Recorder
+
+	// IdleTimeout is the maximum amount of time to wait for the next request
+	// from a client, before closing the connection. Zero means no timeout.
+	IdleTimeout time.Duration
+
+	inShutdown bool // guarded by mu
 }
 
+// Validation is the strictness of protocol validation on a Server.
+type Validation int
+
+const (
+	// ValidateOff passes all messages through without validation.
+	ValidateOff Validation = iota
+
+	// ValidateLog logs violations of the protocol in requests and
+	// responses, but handles invalid requests as usual.
+	ValidateLog
+
+	// ValidateReject logs violations like ValidateLog, and rejects invalid
+	// requests without passing them to the Handler. The client gets a
+	// response which is not OK, with the violations as screen messages.
+	ValidateReject
+)
+
+// ErrServerClosed is returned by Server.Run after a call to Shutdown or Close.
+var ErrServerClosed = errors.New("sip2: Server closed")
+
 // NewServer returns a new SIP2 Server using the given handler. It will return an
 // error if it fails to bind a listener to the given port.
 func NewServer(h Handler, port int) (s *Server, err error) {
-	s = &Server{
-		handler: h,
+	return NewServerAddr(h, ":"+strconv.Itoa(port))
+}
+
+// NewServerAddr returns a new SIP2 Server using the given handler, listening
+// on the given TCP network address, ex: "10.0.0.1:6001". It will return an
+// error if it fails to bind a listener to the address.
+func NewServerAddr(h Handler, addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
 	}
+	return newServer(h, ln), nil
+}
 
-	s.ln, err = net.Listen("tcp", ":"+strconv.Itoa(port))
+// NewTLSServer returns a new SIP2 Server using the given handler, accepting
+// TLS connections on the given TCP network address. The config must contain
+// at least one certificate. To require clients to authenticate with a
+// certificate, set config.ClientAuth and config.ClientCAs. See also TLSConfig.
+func NewTLSServer(h Handler, addr string, config *tls.Config) (*Server, error) {
+	ln, err := tls.Listen("tcp", addr, config)
 	if err != nil {
-		return
+		return nil, err
 	}
+	return newServer(h, ln), nil
+}
 
-	return s, err
+func newServer(h Handler, ln net.Listener) *Server {
+	return &Server{
+		ln:         ln,
+		handler:    h,
+		sessions:   make(map[*Session]bool),
+		violations: make(map[string]int),
+	}
 }
 
+// TLSConfig returns a TLS configuration for NewTLSServer using the certificate
+// and private key in the given PEM encoded files. If clientCAFile is not empty,
+// clients are required to present a certificate signed by one of the
+// certificate authorities in that file.
+func TLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		b, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("sip2: no certificates found in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// Addr returns the network address the Server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// UseAuth makes the Server require clients to log in before any other request
+// is accepted. The credentials of Login requests are verified by the given
+// Authenticator, and the Server responds to them without involving the Handler.
+//
+// Requests from clients which have not logged in are answered with a Login
+// response which is not OK. UseAuth(nil) disables authentication again.
+func (s *Server) UseAuth(a Authenticator) {
+	s.useAuth = a != nil
+	s.auth = a
+}
+
+// Status is a snapshot of the clients connected to a Server.
 type Status struct {
 	ClientsConnected int
 	ClientIPs        []string
+	Clients          []ClientStatus
+}
+
+// ClientStatus describes a connected client.
+type ClientStatus struct {
+	IP            string
+	User          string
+	Location      string
+	Authenticated bool
+	OnlineSince   time.Time
+	Violations    int // protocol violations in requests during the session
 }
 
+// Status returns the currently connected clients, ordered by the time they connected.
 func (s *Server) Status() Status {
-	return Status{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{
+		ClientsConnected: len(s.sessions),
+		ClientIPs:        make([]string, 0, len(s.sessions)),
+		Clients:          make([]ClientStatus, 0, len(s.sessions)),
+	}
+	for sess := range s.sessions {
+		st.Clients = append(st.Clients, ClientStatus{
+			IP:            remoteIP(sess.RemoteAddr()),
+			User:          sess.User(),
+			Location:      sess.Location(),
+			Authenticated: sess.Authenticated(),
+			OnlineSince:   sess.OnlineSince(),
+			Violations:    sess.Violations(),
+		})
+	}
+	sort.Slice(st.Clients, func(i, j int) bool {
+		return st.Clients[i].OnlineSince.Before(st.Clients[j].OnlineSince)
+	})
+	for _, c := range st.Clients {
+		st.ClientIPs = append(st.ClientIPs, c.IP)
+	}
+	return st
 }
 
-func (s *Server) Run() {
+func remoteIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// Run accepts connections and serves clients until the Server is closed. It always
+// returns a non-nil error; after Shutdown or Close the error is ErrServerClosed.
+func (s *Server) Run() error {
+	var delay time.Duration // how long to sleep on accept failure
 	for {
 		conn, err := s.ln.Accept()
 		if err != nil {
-			//println(err.Error())
+			if s.shuttingDown() {
+				return ErrServerClosed
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			// Possibly temporary failure, like running out of file
+			// descriptors; back off and try again.
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else if delay *= 2; delay > time.Second {
+				delay = time.Second
+			}
+			s.logger().Error("sip2: accept failed", "error", err, "retry", delay)
+			time.Sleep(delay)
 			continue
 		}
+		delay = 0
 		go s.handle(conn)
 	}
 }
 
+func (s *Server) shuttingDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inShutdown
+}
+
 func (s *Server) handle(c net.Conn) {
 	r := bufio.NewReader(c)
 	defer c.Close()
+	sess := newSession(c)
+	sess.charset = s.Charset
+	if cs, ok := s.ClientCharsets[remoteIP(c.RemoteAddr())]; ok {
+		sess.charset = cs
+	}
+	s.mu.Lock()
+	if s.inShutdown {
+		s.mu.Unlock()
+		return
+	}
+	s.sessions[sess] = true
+	s.mu.Unlock()
+	client := remoteIP(c.RemoteAddr())
+	if s.Metrics != nil {
+		s.Metrics.connected(1)
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, sess)
+		s.mu.Unlock()
+		if s.Metrics != nil {
+			s.Metrics.connected(-1)
+		}
+		if sc, ok := s.handler.(SessionCloser); ok {
+			sc.CloseSession(sess)
+		}
+	}()
 	for {
-		b, err := r.ReadBytes('\r')
+		sess.setIdle()
+		if s.shuttingDown() {
+			return
+		}
+		if s.IdleTimeout > 0 {
+			c.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		// The session is active from the first byte of a request, so that
+		// a graceful shutdown does not close the connection between reading
+		// the request and writing the response.
+		_, err := r.Peek(1)
+		if err == nil && !sess.activate() {
+			return
+		}
+		var b []byte
+		if err == nil {
+			b, err = r.ReadBytes('\r')
+		}
 		if err != nil {
-			if err != io.EOF {
-				println(err.Error())
+			if err != io.EOF && !s.shuttingDown() && !isTimeout(err) {
+				s.logger().Warn("sip2: read failed", "client", c.RemoteAddr(), "error", err)
 			}
 			return
 		}
 
+		s.record(c.RemoteAddr(), true, b)
+		start := time.Now()
+		req, err := DecodeCharset(b, sess.charset)
 		if s.Log {
-			log.Printf("[%v] -> %s", c.RemoteAddr(), string(b))
+			if err == nil {
+				s.logger().Info("sip2 request", "client", c.RemoteAddr(), "message", mask(req))
+			} else {
+				s.logger().Info("sip2 request", "client", c.RemoteAddr(), "message", maskRaw(b))
+			}
 		}
-		req, err := Decode(b)
-		if err != nil {
-			println(err.Error())
+		var resp Message
+		switch {
+		case err == ErrChecksum:
+			// Ask the SC to resend the garbled request.
+			resp = NewMessage(MsgRespResend).AddField(Field{Type: FieldChecksum})
+			s.countError("checksum", client)
+		case err != nil:
+			s.logger().Warn("sip2: invalid request", "client", c.RemoteAddr(), "error", err)
+			s.countError("decode", client)
 			continue // TODO or return?
+		case req.Type() == MsgReqResend:
+			if sess.lastMessage.typ == MsgUnknown {
+				// Nothing to resend
+				continue
+			}
+			resp = sess.lastMessage
+		default:
+			if errs := s.validate(sess, req); len(errs) > 0 && s.Validation == ValidateReject {
+				resp = notOKResponse(req, s.InstitutionID, "Invalid request")
+				for _, e := range errs {
+					resp.AddField(Field{Type: FieldScreenMessage, Value: e})
+				}
+			} else {
+				resp = s.dispatch(sess, req)
+				s.validateResponse(sess, resp)
+			}
+			if resp.fields != nil {
+				if seq, ok := req.FieldOK(FieldSequenceNumber); ok {
+					resp.AddField(Field{Type: FieldSequenceNumber, Value: seq})
+				}
+				if _, ok := req.FieldOK(FieldChecksum); ok || s.ErrorDetection {
+					resp.AddField(Field{Type: FieldChecksum})
+				}
+			}
+			sess.lastMessage = resp
+			if s.Metrics != nil {
+				s.Metrics.observe(req.typ, client, time.Since(start))
+			}
+		}
+
+		out, err := resp.encode(sess.charset)
+		var encErr *EncodingError
+		if errors.As(err, &encErr) {
+			// Send the response with the characters replaced.
+			s.logger().Warn("sip2: response not fully encoded", "client", c.RemoteAddr(), "error", err)
+		} else if err != nil {
+			s.logger().Error("sip2: invalid response", "client", c.RemoteAddr(), "error", err)
+			s.countError("encode", client)
+			return
 		}
-		resp := s.handler.Handle(req)
-		if err := resp.Encode(c); err != nil {
+		s.record(c.RemoteAddr(), false, out)
+		if _, err := c.Write(out); err != nil {
 			if err != io.EOF {
-				println(err.Error())
+				s.logger().Warn("sip2: write failed", "client", c.RemoteAddr(), "error", err)
 			}
 			return
 		}
 		if s.Log {
-			// TODO avoid encoding twice
-			var b bytes.Buffer
-			resp.Encode(&b)
-			log.Printf("[%v] <- %s", c.RemoteAddr(), b.String())
+			s.logger().Info("sip2 response", "client", c.RemoteAddr(), "message", mask(resp))
+		}
+	}
+}
+
+// record records a message to the Recorder, if any.
+func (s *Server) record(client net.Addr, request bool, b []byte) {
+	if s.Recorder == nil {
+		return
+	}
+	if err := s.Recorder.Record(client.String(), request, b); err != nil {
+		s.logger().Error("sip2: recording failed", "error", err)
+	}
+}
+
+// logger returns the Logger of the Server.
+func (s *Server) logger() Logger {
+	if s.Logger == nil {
+		return stdLogger{}
+	}
+	return s.Logger
+}
+
+// countError counts an error in the Metrics, if any.
+func (s *Server) countError(reason, client string) {
+	if s.Metrics != nil {
+		s.Metrics.countError(reason, client)
+	}
+}
+
+// dispatch returns the response to a request, enforcing authentication
+// if required, and recording logins in the Session.
+func (s *Server) dispatch(sess *Session, req Message) Message {
+	if s.useAuth {
+		if req.Type() == MsgReqLogin {
+			ok := s.auth.Authenticate(req.Field(FieldLoginUserID), req.Field(FieldLoginPassword), req.Field(FieldLocationCode))
+			sess.login(req, ok)
+			return loginResponse(ok)
+		}
+		if !sess.Authenticated() {
+			return loginResponse(false)
+		}
+	}
+
+	var resp Message
+	if sh, ok := s.handler.(SessionHandler); ok {
+		resp = sh.HandleSession(sess, req)
+	} else {
+		resp = s.handler.Handle(req)
+	}
+
+	if req.Type() == MsgReqLogin && resp.Type() == MsgRespLogin {
+		sess.login(req, resp.Field(FieldOK) == "1")
+	}
+	return resp
+}
+
+// validate validates a request according to the Validation setting, logging
+// and counting any violations.
+func (s *Server) validate(sess *Session, req Message) []string {
+	if s.Validation == ValidateOff {
+		return nil
+	}
+	errs := req.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+	s.logger().Warn("sip2: invalid request", "client", sess.RemoteAddr(), "type", req.typ, "violations", strings.Join(errs, "; "))
+	s.countError("invalid", remoteIP(sess.RemoteAddr()))
+	sess.addViolations(len(errs))
+	s.mu.Lock()
+	s.violations[remoteIP(sess.RemoteAddr())] += len(errs)
+	s.mu.Unlock()
+	return errs
+}
+
+// validateResponse logs any violations in a response from the Handler.
+func (s *Server) validateResponse(sess *Session, resp Message) {
+	if s.Validation == ValidateOff || resp.fields == nil {
+		return
+	}
+	if errs := resp.Validate(); len(errs) > 0 {
+		s.logger().Warn("sip2: handler sent invalid response", "client", sess.RemoteAddr(), "type", resp.typ, "violations", strings.Join(errs, "; "))
+	}
+}
+
+// Violations returns the number of protocol violations found in requests
+// from each client IP since the Server started. Violations are only counted
+// when Validation is enabled.
+func (s *Server) Violations() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string]int, len(s.violations))
+	for ip, n := range s.violations {
+		res[ip] = n
+	}
+	return res
+}
+
+func loginResponse(ok bool) Message {
+	if ok {
+		return NewMessage(MsgRespLogin).AddField(Field{Type: FieldOK, Value: "1"})
+	}
+	return NewMessage(MsgRespLogin).AddField(Field{Type: FieldOK, Value: "0"})
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+// Shutdown gracefully shuts down the Server. It stops accepting new
+// connections, closes idle connections, and waits for transactions in
+// progress to complete before closing the remaining connections.
+//
+// If the context expires before all connections are closed, they are closed
+// forcibly and the context's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.inShutdown = true
+	s.mu.Unlock()
+	s.ln.Close()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if s.closeIdle() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			s.closeAll()
+			return ctx.Err()
+		case <-ticker.C:
 		}
+	}
+}
+
+// closeIdle closes all connections which are not in the middle of a
+// transaction, and reports whether all connections are closed.
+func (s *Server) closeIdle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sess := range s.sessions {
+		sess.closeIfIdle()
+	}
+	return len(s.sessions) == 0
+}
 
+func (s *Server) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sess := range s.sessions {
+		sess.conn.Close()
 	}
 }
 
+// Close immediately closes the listener and all client connections.
+// For a graceful shutdown, use Shutdown.
 func (s *Server) Close() {
-	// TODO first close all connected clients?
+	s.mu.Lock()
+	s.inShutdown = true
+	s.mu.Unlock()
 	if s.ln != nil {
 		s.ln.Close()
 	}
+	s.closeAll()
 }
@@ -2,7 +2,20 @@ package sip2
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -232,3 +245,427 @@ func TestTransactionSession(t *testing.T) {
 func localAddr(s string) string {
 	return s[strings.LastIndex(s, ":"):]
 }
+
+func TestServerErrorDetection(t *testing.T) {
+	h := newTestHandler([]string{"Sult - Knut Hamsun"}, []string{"Ole Jensen"})
+
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	client, err := net.Dial("tcp", localAddr(s.ln.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	r := bufio.NewReader(client)
+
+	read := func() Message {
+		b, err := r.ReadBytes('\r')
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := Decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	// 1) Request with sequence number and checksum gets response with same sequence number.
+	sendMsg(t, client,
+		testMF.NewMessage(MsgReqItemInformation).AddField(
+			Field{Type: FieldItemIdentifier, Value: "0"},
+			Field{Type: FieldSequenceNumber, Value: "7"},
+		))
+	resp := read()
+	if resp.Type() != MsgRespItemInformation || resp.Field(FieldSequenceNumber) != "7" {
+		t.Fatalf("got %v with sequence number %q; want %v with sequence number 7",
+			resp.Type(), resp.Field(FieldSequenceNumber), MsgRespItemInformation)
+	}
+
+	// 2) Request with bad checksum gets a 96 resend response.
+	if _, err := client.Write([]byte("1720160822    153450AO|AB0|AY8AZ0000\r")); err != nil {
+		t.Fatal(err)
+	}
+	if resp := read(); resp.Type() != MsgRespResend {
+		t.Fatalf("got %v; want %v", resp.Type(), MsgRespResend)
+	}
+
+	// 3) Resend request (97) gets the last response replayed.
+	sendMsg(t, client, NewMessage(MsgReqResend).AddField(Field{Type: FieldChecksum}))
+	resend := read()
+	if resend.String() != resp.String() {
+		t.Errorf("resend got %q; want %q", resend.String(), resp.String())
+	}
+}
+
+// sessionHandler is a testHandler which adds the login user and location of the
+// client's session as a screen message to every response.
+type sessionHandler struct {
+	*testHandler
+}
+
+func (h sessionHandler) HandleSession(sess *Session, req Message) Message {
+	return h.Handle(req).AddField(Field{Type: FieldScreenMessage, Value: sess.User() + "@" + sess.Location()})
+}
+
+func TestServerAuth(t *testing.T) {
+	h := sessionHandler{newTestHandler([]string{"Sult - Knut Hamsun"}, []string{"Ole Jensen"})}
+
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.UseAuth(AuthenticatorFunc(func(user, password, location string) bool {
+		return user == "kiosk" && password == "secret"
+	}))
+	go s.Run()
+	defer s.Close()
+
+	client, err := net.Dial("tcp", localAddr(s.ln.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	itemInfo := testMF.NewMessage(MsgReqItemInformation).AddField(
+		Field{Type: FieldItemIdentifier, Value: "0"},
+	)
+	login := func(password string) Message {
+		return NewMessage(MsgReqLogin).AddField(
+			Field{Type: FieldUIDAlgorithm, Value: "0"},
+			Field{Type: FieldPWDAlgorithm, Value: "0"},
+			Field{Type: FieldLoginUserID, Value: "kiosk"},
+			Field{Type: FieldLoginPassword, Value: password},
+			Field{Type: FieldLocationCode, Value: "Furuset"},
+		)
+	}
+
+	// 1) Not logged in
+	sendMsg(t, client, itemInfo)
+	wantMsg(t, client, MsgRespLogin, Field{Type: FieldOK, Value: "0"})
+
+	// 2) Login with wrong password
+	sendMsg(t, client, login("wrong"))
+	wantMsg(t, client, MsgRespLogin, Field{Type: FieldOK, Value: "0"})
+
+	sendMsg(t, client, itemInfo)
+	wantMsg(t, client, MsgRespLogin, Field{Type: FieldOK, Value: "0"})
+
+	// 3) Login OK
+	sendMsg(t, client, login("secret"))
+	wantMsg(t, client, MsgRespLogin, Field{Type: FieldOK, Value: "1"})
+
+	// 4) Handler sees the session
+	sendMsg(t, client, itemInfo)
+	wantMsg(t, client, MsgRespItemInformation,
+		Field{Type: FieldTitleIdentifier, Value: "Sult - Knut Hamsun"},
+		Field{Type: FieldScreenMessage, Value: "kiosk@Furuset"},
+	)
+
+	st := s.Status()
+	if st.ClientsConnected != 1 || len(st.Clients) != 1 {
+		t.Fatalf("Status() got %d clients connected; want 1", st.ClientsConnected)
+	}
+	if c := st.Clients[0]; !c.Authenticated || c.User != "kiosk" || c.Location != "Furuset" || c.IP != st.ClientIPs[0] {
+		t.Errorf("Status() got client %+v", c)
+	}
+}
+
+func TestServerAuthNil(t *testing.T) {
+	s, err := NewServer(NewRouter(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.UseAuth(AuthenticatorFunc(func(user, password, location string) bool { return false }))
+	s.UseAuth(nil)
+	go s.Run()
+	defer s.Close()
+
+	// Logins and other requests go to the Handler.
+	c := NewClient(s.Addr().String(), testMF)
+	defer c.Close()
+	if err := c.Login("user", "pass", ""); err != nil {
+		t.Fatalf("Login with authentication disabled => %v", err)
+	}
+	if _, err := c.Status(); err != nil {
+		t.Fatalf("Status with authentication disabled => %v", err)
+	}
+}
+
+// writeTestCert writes a self-signed certificate and key, valid for localhost
+// and usable for both server and client authentication, to dir.
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSServer(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir())
+
+	config, err := TLSConfig(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := loginHandler{newTestHandler([]string{"Sult - Knut Hamsun"}, nil), "kiosk", "secret"}
+	s, err := NewTLSServer(h, "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Run()
+	defer s.Close()
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Client without certificate is rejected
+	c := NewClient(s.Addr().String(), testMF)
+	c.Retries = 0
+	c.TLSConfig = &tls.Config{RootCAs: config.ClientCAs}
+	if _, err := c.Status(); err == nil {
+		t.Error("Status without client certificate got nil; want error")
+	}
+	c.Close()
+
+	// Client with certificate
+	c = NewClient(s.Addr().String(), testMF)
+	c.TLSConfig = &tls.Config{RootCAs: config.ClientCAs, Certificates: []tls.Certificate{cert}}
+	defer c.Close()
+	if _, err := c.Status(); err != nil {
+		t.Fatalf("Status with client certificate failed: %v", err)
+	}
+}
+
+// blockingHandler blocks on checkout requests until released.
+type blockingHandler struct {
+	*testHandler
+	started chan bool
+	release chan bool
+}
+
+func (h blockingHandler) Handle(req Message) Message {
+	if req.Type() == MsgReqCheckout {
+		h.started <- true
+		<-h.release
+	}
+	return h.testHandler.Handle(req)
+}
+
+func TestServerShutdown(t *testing.T) {
+	h := blockingHandler{
+		testHandler: newTestHandler([]string{"Sult - Knut Hamsun"}, []string{"Ole Jensen"}),
+		started:     make(chan bool),
+		release:     make(chan bool),
+	}
+	s, err := NewServerAddr(h, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error)
+	go func() { runErr <- s.Run() }()
+
+	busy, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	idle, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+
+	// Wait for both connections to be registered
+	for s.Status().ClientsConnected != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	sendMsg(t, busy,
+		testMF.NewMessage(MsgReqCheckout).AddField(
+			Field{Type: FieldPatronIdentifier, Value: "0"},
+			Field{Type: FieldItemIdentifier, Value: "0"},
+		))
+	<-h.started
+
+	shutdownErr := make(chan error)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+
+	// The idle connection is closed
+	idle.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := bufio.NewReader(idle).ReadBytes('\r'); err != io.EOF {
+		t.Errorf("idle connection read got %v; want %v", err, io.EOF)
+	}
+
+	// The transaction in progress is completed
+	h.release <- true
+	wantMsg(t, busy, MsgRespCheckout, Field{Type: FieldOK, Value: "1"})
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown got %v; want nil", err)
+	}
+	if err := <-runErr; err != ErrServerClosed {
+		t.Errorf("Run got %v; want %v", err, ErrServerClosed)
+	}
+}
+
+func TestServerShutdownPartialRequest(t *testing.T) {
+	s, err := NewServerAddr(newTestHandler([]string{"Sult - Knut Hamsun"}, []string{"Ole Jensen"}), "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runErr := make(chan error)
+	go func() { runErr <- s.Run() }()
+
+	c, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Send all of a request but the terminator, and wait for the session
+	// to become active.
+	var b bytes.Buffer
+	req := testMF.NewMessage(MsgReqCheckout).AddField(
+		Field{Type: FieldPatronIdentifier, Value: "0"},
+		Field{Type: FieldItemIdentifier, Value: "0"},
+	)
+	if err := req.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	c.Write(bytes.TrimSuffix(b.Bytes(), []byte("\r")))
+	for active := false; !active; {
+		time.Sleep(time.Millisecond)
+		s.mu.Lock()
+		for sess := range s.sessions {
+			sess.mu.Lock()
+			active = sess.active
+			sess.mu.Unlock()
+		}
+		s.mu.Unlock()
+	}
+
+	shutdownErr := make(chan error)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// The request being read is completed, and gets a response.
+	c.Write([]byte("\r"))
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	wantMsg(t, c, MsgRespCheckout, Field{Type: FieldOK, Value: "1"})
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown got %v; want nil", err)
+	}
+	if err := <-runErr; err != ErrServerClosed {
+		t.Errorf("Run got %v; want %v", err, ErrServerClosed)
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	s, err := NewServerAddr(newTestHandler(nil, nil), "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.IdleTimeout = 20 * time.Millisecond
+	go s.Run()
+	defer s.Close()
+
+	c, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := bufio.NewReader(c).ReadBytes('\r'); err != io.EOF {
+		t.Errorf("read from idle connection got %v; want %v", err, io.EOF)
+	}
+}
+
+func TestServerValidation(t *testing.T) {
+	h := newTestHandler([]string{"Sult - Knut Hamsun"}, []string{"Ole Jensen"})
+
+	s, err := NewServer(h, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Validation = ValidateReject
+	go s.Run()
+	defer s.Close()
+
+	client, err := net.Dial("tcp", localAddr(s.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	invalid := testMF.NewMessage(MsgReqCheckout).AddField(
+		Field{Type: FieldNoBlock, Value: "X"},
+		Field{Type: FieldPatronIdentifier, Value: "0"},
+		Field{Type: FieldItemIdentifier, Value: "0"},
+	)
+
+	sendMsg(t, client, invalid)
+	resp := getMsg(t, client, MsgRespCheckout)
+	if resp.Field(FieldOK) != "0" || resp.Field(FieldPatronIdentifier) != "0" {
+		t.Errorf("invalid request got response %v; want rejection", resp)
+	}
+	if got := resp.Fields(FieldScreenMessage); len(got) != 2 || !strings.Contains(got[1], "FieldNoBlock") {
+		t.Errorf("rejection screen messages = %q; want violation of FieldNoBlock", got)
+	}
+
+	st := s.Status()
+	if len(st.Clients) != 1 || st.Clients[0].Violations != 1 {
+		t.Errorf("Status().Clients = %+v; want 1 client with 1 violation", st.Clients)
+	}
+	if v := s.Violations(); v["127.0.0.1"] != 1 {
+		t.Errorf("Violations() = %v; want 1 for 127.0.0.1", v)
+	}
+
+	// In log mode, invalid requests are handled.
+	s.Validation = ValidateLog
+	sendMsg(t, client, invalid)
+	wantMsg(t, client, MsgRespCheckout, Field{Type: FieldOK, Value: "1"})
+	if v := s.Violations(); v["127.0.0.1"] != 2 {
+		t.Errorf("Violations() = %v; want 2 for 127.0.0.1", v)
+	}
+}
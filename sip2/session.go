@@ -0,0 +1,152 @@
+package sip2
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Session holds the state of a client connected to a Server.
+type Session struct {
+	conn        net.Conn
+	onlineSince time.Time
+	lastMessage Message // last response sent, replayed on resend requests
+	charset     Charset
+
+	mu            sync.Mutex
+	active        bool // in the middle of a transaction
+	closed        bool // closed by a graceful shutdown
+	authenticated bool
+	user          string
+	location      string
+	violations    int
+}
+
+func newSession(c net.Conn) *Session {
+	return &Session{
+		conn:        c,
+		onlineSince: time.Now(),
+	}
+}
+
+// RemoteAddr returns the network address of the client.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// OnlineSince returns the time the client connected.
+func (s *Session) OnlineSince() time.Time {
+	return s.onlineSince
+}
+
+// Charset returns the character encoding used for the client's messages.
+func (s *Session) Charset() Charset {
+	return s.charset
+}
+
+// Authenticated reports whether the client has logged in successfully.
+func (s *Session) Authenticated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.authenticated
+}
+
+// User returns the login user ID (CN) the client logged in with, or
+// an empty string if the client has not logged in.
+func (s *Session) User() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+// Location returns the location code (CP) the client gave when logging in,
+// which identifies the location of the terminal.
+func (s *Session) Location() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location
+}
+
+// Violations returns the number of protocol violations found in requests
+// from the client, when the Server validates requests.
+func (s *Session) Violations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.violations
+}
+
+func (s *Session) addViolations(n int) {
+	s.mu.Lock()
+	s.violations += n
+	s.mu.Unlock()
+}
+
+func (s *Session) setIdle() {
+	s.mu.Lock()
+	s.active = false
+	s.mu.Unlock()
+}
+
+// activate marks the session as in the middle of a transaction, and
+// reports whether it may go ahead; it may not if the connection has been
+// closed as idle.
+func (s *Session) activate() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.active = true
+	return true
+}
+
+// closeIfIdle closes the connection, unless the session is in the middle
+// of a transaction.
+func (s *Session) closeIfIdle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		s.closed = true
+		s.conn.Close()
+	}
+}
+
+// login records the result of a login request.
+func (s *Session) login(req Message, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authenticated = ok
+	if ok {
+		s.user = req.Field(FieldLoginUserID)
+		s.location = req.Field(FieldLocationCode)
+	}
+}
+
+// SessionHandler can be implemented by a Handler which needs to know the
+// Session of the client sending a request. If the Handler given to a Server
+// implements SessionHandler, HandleSession is called instead of Handle.
+type SessionHandler interface {
+	Handler
+	HandleSession(sess *Session, request Message) (response Message)
+}
+
+// SessionCloser can be implemented by a Handler which keeps state per
+// Session. If the Handler given to a Server implements SessionCloser,
+// CloseSession is called when the client disconnects.
+type SessionCloser interface {
+	CloseSession(sess *Session)
+}
+
+// Authenticator verifies the credentials given by a client in a Login request.
+type Authenticator interface {
+	Authenticate(user, password, location string) bool
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as
+// Authenticators.
+type AuthenticatorFunc func(user, password, location string) bool
+
+// Authenticate calls f(user, password, location).
+func (f AuthenticatorFunc) Authenticate(user, password, location string) bool {
+	return f(user, password, location)
+}
@@ -4,10 +4,11 @@
 package sip2
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -45,7 +46,7 @@ func (m Message) Type() msgType {
 // will overwrite any existing value for the field.
 func (m Message) AddField(fs ...Field) Message {
 	for _, f := range fs {
-		if repeatableField[f.Type] {
+		if isRepeatable(f.Type) {
 			m.repeateableFields[f.Type] = append(m.repeateableFields[f.Type], f.Value)
 		} else {
 			m.fields[f.Type] = f.Value
@@ -54,6 +55,19 @@ func (m Message) AddField(fs ...Field) Message {
 	return m
 }
 
+// clone returns a copy of the Message, which can be modified without
+// affecting the original.
+func (m Message) clone() Message {
+	c := NewMessage(m.typ)
+	for f, v := range m.fields {
+		c.fields[f] = v
+	}
+	for f, vs := range m.repeateableFields {
+		c.repeateableFields[f] = append([]string(nil), vs...)
+	}
+	return c
+}
+
 // String encodes the SIP message to a string.
 func (m Message) String() string {
 	var b bytes.Buffer
@@ -67,7 +81,7 @@ func (m Message) String() string {
 // If the field is repeatable and several are defined,
 // only one of them will be returned
 func (m Message) Field(f fieldType) string {
-	if repeatableField[f] && len(m.repeateableFields[f]) > 0 {
+	if isRepeatable(f) && len(m.repeateableFields[f]) > 0 {
 		return m.repeateableFields[f][0]
 	}
 	return m.fields[f]
@@ -78,7 +92,7 @@ func (m Message) Field(f fieldType) string {
 // defined, only one of them will be returned
 func (m Message) FieldOK(f fieldType) (string, bool) {
 	v, ok := m.fields[f]
-	if !ok && repeatableField[f] {
+	if !ok && isRepeatable(f) {
 		if len(m.repeateableFields[f]) > 0 {
 			v = m.repeateableFields[f][0]
 			ok = true
@@ -100,38 +114,71 @@ func (m Message) Fields(f fieldType) []string {
 // It will fail if there are missing required fields, or if
 // writing to the stream fails.
 // Unknown fields and defined fields which are not required or
-// optional for the give type are not encoded.
+// optional for the give type are not encoded. Extension fields registered
+// with RegisterExtension are encoded after the fields defined by the protocol.
+//
+// If the message has a FieldSequenceNumber or a FieldChecksum, the message
+// is encoded in error detection mode, with the sequence number (AY) and a
+// checksum (AZ) computed over the encoded message appended to it. The value of
+// FieldChecksum is ignored.
 func (m Message) Encode(w io.Writer) error {
-	bw := bufio.NewWriter(w)
-	if _, err := bw.WriteString(msgToCode[m.typ]); err != nil {
+	b, err := m.encode(UTF8)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// EncodeCharset is like Encode, but encodes the message in the given Charset.
+// Characters which cannot be represented in the Charset are replaced with '?',
+// and reported in an *EncodingError, which is returned after the message has
+// been written.
+func (m Message) EncodeCharset(w io.Writer, c Charset) error {
+	b, encErr := m.encode(c)
+	if b == nil {
+		return encErr
+	}
+	if _, err := w.Write(b); err != nil {
 		return err
 	}
+	return encErr
+}
+
+// encode returns the encoded message in the given Charset. If some characters
+// cannot be represented in the Charset, the message is returned along with an
+// *EncodingError. Any other error means the message cannot be encoded.
+func (m Message) encode(c Charset) ([]byte, error) {
+	var bw bytes.Buffer
+	if _, err := bw.WriteString(msgToCode[m.typ]); err != nil {
+		return nil, err
+	}
 
 	for _, f := range msgDefinitions[m.typ].RequiredFixed {
 		if !m.hasField(f) {
-			return fmt.Errorf("Encode: %v missing required fixed-length field: %v", m.typ, f)
+			return nil, fmt.Errorf("Encode: %v missing required fixed-length field: %v", m.typ, f)
 		}
 
 		if _, err := bw.WriteString(m.fields[f]); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	for _, f := range msgDefinitions[m.typ].RequiredVar {
 		if !m.hasField(f) { // TODO leave out?
-			return fmt.Errorf("Encode: %v missing required variable-length field: %v", m.typ, f)
+			return nil, fmt.Errorf("Encode: %v missing required variable-length field: %v", m.typ, f)
 		}
 
 		if _, err := bw.WriteString(fieldToCode[f]); err != nil {
-			return err
+			return nil, err
 		}
 
 		if _, err := bw.WriteString(m.fields[f]); err != nil {
-			return err
+			return nil, err
 		}
 
 		if _, err := bw.WriteRune('|'); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -141,15 +188,15 @@ func (m Message) Encode(w io.Writer) error {
 		}
 
 		if _, err := bw.WriteString(fieldToCode[f]); err != nil {
-			return err
+			return nil, err
 		}
 
 		if _, err := bw.WriteString(m.fields[f]); err != nil {
-			return err
+			return nil, err
 		}
 
 		if _, err := bw.WriteRune('|'); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -160,25 +207,56 @@ func (m Message) Encode(w io.Writer) error {
 
 		for _, s := range fs {
 			if _, err := bw.WriteString(fieldToCode[f]); err != nil {
-				return err
+				return nil, err
 			}
 
 			if _, err := bw.WriteString(s); err != nil {
-				return err
+				return nil, err
 			}
 
 			if _, err := bw.WriteRune('|'); err != nil {
-				return err
+				return nil, err
 			}
 		}
 
 	}
 
+	m.encodeExtensions(&bw)
+
+	var encErr error
+	if c != UTF8 {
+		var b []byte
+		b, encErr = c.encode(bw.String())
+		bw.Reset()
+		bw.Write(b)
+	}
+
+	seq, useSeq := m.fields[FieldSequenceNumber]
+	if _, useChecksum := m.fields[FieldChecksum]; useSeq || useChecksum {
+		if useSeq {
+			bw.WriteString(fieldToCode[FieldSequenceNumber])
+			bw.WriteString(seq)
+		}
+		bw.WriteString(fieldToCode[FieldChecksum])
+		bw.WriteString(checksum(bw.Bytes()))
+	}
+
 	if _, err := bw.WriteRune('\r'); err != nil {
-		return err
+		return nil, err
 	}
 
-	return bw.Flush()
+	return bw.Bytes(), encErr
+}
+
+// checksum computes the SIP2 checksum of a message, which is the two's complement
+// of the 16-bit sum of all the bytes of the message, up to and including
+// the AZ field identifier, formatted as 4 hexadecimal digits.
+func checksum(b []byte) string {
+	var sum uint16
+	for _, c := range b {
+		sum += uint16(c)
+	}
+	return fmt.Sprintf("%04X", -sum)
 }
 
 func (m Message) hasField(f fieldType) bool {
@@ -187,7 +265,9 @@ func (m Message) hasField(f fieldType) bool {
 }
 
 // Validate validates a SIP message. It returns a slice of strings
-// listing the violations of the SIP protocol for the given message type.
+// listing the violations of the SIP protocol for the given message type:
+// missing required fields, fixed-length fields of the wrong length, and
+// field values not matching the pattern of the field.
 func (m Message) Validate() []string {
 	errs := []string{}
 	if m.typ == MsgUnknown {
@@ -196,28 +276,38 @@ func (m Message) Validate() []string {
 	}
 
 	for _, f := range msgDefinitions[m.typ].RequiredFixed {
-		if !m.hasField(f) {
+		v, ok := m.fields[f]
+		if !ok {
 			errs = append(errs, fmt.Sprintf("missing required fixed-length field: %v", f))
 			continue
 		}
-		if rxp := fieldValdiation[f]; rxp != nil {
-			if v, ok := m.fields[f]; ok && !rxp.MatchString(v) {
-				errs = append(errs, fmt.Sprintf("fixed-length field %v with value %q does not match %v",
-					f, v, rxp))
-			}
+		if n := fixedFieldLengths[f]; n > 0 && len(v) != n {
+			errs = append(errs, fmt.Sprintf("fixed-length field %v with value %q has length %d; want %d",
+				f, v, len(v), n))
+			continue
+		}
+		if !validValue(f, v, true) {
+			errs = append(errs, fmt.Sprintf("fixed-length field %v with value %q does not match %v",
+				f, v, fieldValdiation[f]))
 		}
 	}
 
 	for _, f := range msgDefinitions[m.typ].RequiredVar {
-		if !m.hasField(f) {
+		v, ok := m.fields[f]
+		if !ok {
 			errs = append(errs, fmt.Sprintf("missing required variable-length field: %v", f))
 			continue
 		}
-		if rxp := fieldValdiation[f]; rxp != nil {
-			if v, ok := m.fields[f]; ok && !rxp.MatchString(v) {
-				errs = append(errs, fmt.Sprintf("variable-length field %v with value %q does not match %v",
-					f, v, rxp))
-			}
+		if !validValue(f, v, false) {
+			errs = append(errs, fmt.Sprintf("variable-length field %v with value %q does not match %v",
+				f, v, fieldValdiation[f]))
+		}
+	}
+
+	for _, f := range msgDefinitions[m.typ].OptionalVar {
+		if v, ok := m.fields[f]; ok && !validValue(f, v, false) {
+			errs = append(errs, fmt.Sprintf("variable-length field %v with value %q does not match %v",
+				f, v, fieldValdiation[f]))
 		}
 	}
 
@@ -229,6 +319,28 @@ func (m Message) Validate() []string {
 	return errs
 }
 
+// anchoredValidation holds the patterns of fieldValdiation anchored to match
+// the whole value.
+var anchoredValidation = func() map[fieldType]*regexp.Regexp {
+	res := make(map[fieldType]*regexp.Regexp, len(fieldValdiation))
+	for f, rxp := range fieldValdiation {
+		res[f] = regexp.MustCompile(`^(?:` + strings.TrimSuffix(rxp.String(), "$") + `)$`)
+	}
+	return res
+}()
+
+// validValue reports whether the value matches the pattern of the field,
+// which is a fixed-length field of the message if fixed is true. Empty values
+// of variable-length fields are allowed. A field may be fixed-length in one
+// message type and variable-length in others, such as the currency type.
+func validValue(f fieldType, v string, fixed bool) bool {
+	rxp := anchoredValidation[f]
+	if rxp == nil || (v == "" && !fixed) {
+		return true
+	}
+	return rxp.MatchString(v)
+}
+
 // MessageFactory can generate Message with default values set.
 // Some fields will always have the same value in a lot of
 // configurations, so this is provided as a convenience in those cases.
@@ -334,6 +446,7 @@ const (
 	MsgRespHold              // 16
 	MsgRespRenew             // 30
 	MsgRespRenewAll          // 66
+	MsgRespResend            // 96
 )
 
 // fieldType represents a request/response message field ID.
@@ -0,0 +1,374 @@
+package sip2
+
+//go:generate go run gen_messages.go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedMessage is implemented by the typed request and response structs in
+// messages.go, one for each message type, ex: *CheckoutRequest and
+// *PatronInformationResponse. They offer an alternative to working with the
+// string values of a Message, with fields of the natural Go type: bool for
+// Y/N flags, int for counts, time.Time for timestamps, and enum types for
+// coded values.
+//
+// The due date (AH) is the exception, and is a string: unlike the other
+// dates, the protocol does not give it a format, but leaves it to the ACS,
+// and many send it formatted for display to the patron, ex: "01.09.2016".
+//
+// Optional fields are only added to the Message when they have a non-zero
+// value. Optional Y/N flags are therefore *bool, where nil means the field
+// is not present.
+type TypedMessage interface {
+	// Message converts the typed struct to a Message.
+	Message() Message
+
+	// FromMessage sets the fields of the typed struct from a Message. It
+	// returns an error if the Message is of another type, or has a field
+	// value which cannot be converted to the Go type of the field. Missing
+	// fields are left with their zero value.
+	FromMessage(Message) error
+}
+
+// Typed returns the Message converted to the corresponding typed struct,
+// ex: *CheckoutRequest for a MsgReqCheckout.
+func (m Message) Typed() (TypedMessage, error) {
+	tm := newTyped(m.typ)
+	if tm == nil {
+		return nil, fmt.Errorf("sip2: no typed struct for %v", m.typ)
+	}
+	if err := tm.FromMessage(m); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// CirculationStatus is the circulation status of an item (field
+// FieldCirulationStatus).
+type CirculationStatus int
+
+// Circulation statuses as defined by the protocol.
+const (
+	CirculationOther             CirculationStatus = 1
+	CirculationOnOrder           CirculationStatus = 2
+	CirculationAvailable         CirculationStatus = 3
+	CirculationCharged           CirculationStatus = 4
+	CirculationChargedNoRecall   CirculationStatus = 5 // not to be recalled until earliest recall date
+	CirculationInProcess         CirculationStatus = 6
+	CirculationRecalled          CirculationStatus = 7
+	CirculationWaitingOnHold     CirculationStatus = 8 // waiting on hold shelf
+	CirculationWaitingReshelving CirculationStatus = 9
+	CirculationInTransit         CirculationStatus = 10 // between library locations
+	CirculationClaimedReturned   CirculationStatus = 11
+	CirculationLost              CirculationStatus = 12
+	CirculationMissing           CirculationStatus = 13
+)
+
+// SecurityMarker is the type of security marker of an item.
+type SecurityMarker int
+
+// Security markers as defined by the protocol.
+const (
+	SecurityMarkerOther            SecurityMarker = 0
+	SecurityMarkerNone             SecurityMarker = 1
+	SecurityMarkerTattleTape       SecurityMarker = 2 // 3M Tattle-Tape Security Strip
+	SecurityMarkerWhisperTapeStrip SecurityMarker = 3 // 3M Whisper Tape
+)
+
+// FeeType is the type of fee a patron is charged.
+type FeeType int
+
+// Fee types as defined by the protocol.
+const (
+	FeeOther          FeeType = 1 // other/unknown
+	FeeAdministrative FeeType = 2
+	FeeDamage         FeeType = 3
+	FeeOverdue        FeeType = 4
+	FeeProcessing     FeeType = 5
+	FeeRental         FeeType = 6
+	FeeReplacement    FeeType = 7
+	FeeComputerAccess FeeType = 8
+	FeeHold           FeeType = 9
+)
+
+// PaymentType is the means by which a fee is paid.
+type PaymentType int
+
+// Payment types as defined by the protocol.
+const (
+	PaymentCash       PaymentType = 0
+	PaymentVISA       PaymentType = 1
+	PaymentCreditCard PaymentType = 2
+)
+
+// MediaType is the type of material of an item.
+type MediaType int
+
+// Media types as defined by the protocol.
+const (
+	MediaOther             MediaType = 0
+	MediaBook              MediaType = 1
+	MediaMagazine          MediaType = 2
+	MediaBoundJournal      MediaType = 3
+	MediaAudioTape         MediaType = 4
+	MediaVideoTape         MediaType = 5
+	MediaCD                MediaType = 6 // CD/CDROM
+	MediaDiskette          MediaType = 7
+	MediaBookWithDiskette  MediaType = 8
+	MediaBookWithCD        MediaType = 9
+	MediaBookWithAudioTape MediaType = 10
+)
+
+// HoldType is the type of hold placed by a Hold request.
+type HoldType int
+
+// Hold types as defined by the protocol.
+const (
+	HoldOther          HoldType = 1
+	HoldAnyCopy        HoldType = 2 // any copy of a title
+	HoldSpecificCopy   HoldType = 3
+	HoldAnyCopyAtPlace HoldType = 4 // any copy at a single branch or sublocation
+)
+
+// StatusCode is the status of the SC reported in a SC Status request.
+type StatusCode int
+
+// SC status codes as defined by the protocol.
+const (
+	StatusOK           StatusCode = 0
+	StatusOutOfPaper   StatusCode = 1
+	StatusShuttingDown StatusCode = 2
+)
+
+// Language is the language of a patron, or of the SC.
+type Language int
+
+// Languages as defined by the protocol.
+const (
+	LanguageUnknown              Language = 0
+	LanguageEnglish              Language = 1
+	LanguageFrench               Language = 2
+	LanguageGerman               Language = 3
+	LanguageItalian              Language = 4
+	LanguageDutch                Language = 5
+	LanguageSwedish              Language = 6
+	LanguageFinnish              Language = 7
+	LanguageSpanish              Language = 8
+	LanguageDanish               Language = 9
+	LanguagePortuguese           Language = 10
+	LanguageCanadianFrench       Language = 11
+	LanguageNorwegian            Language = 12
+	LanguageHebrew               Language = 13
+	LanguageJapanese             Language = 14
+	LanguageRussian              Language = 15
+	LanguageArabic               Language = 16
+	LanguagePolish               Language = 17
+	LanguageGreek                Language = 18
+	LanguageChinese              Language = 19
+	LanguageKorean               Language = 20
+	LanguageNorthAmericanSpanish Language = 21
+	LanguageTamil                Language = 22
+	LanguageMalay                Language = 23
+	LanguageUnitedKingdom        Language = 24
+	LanguageIcelandic            Language = 25
+	LanguageBelgian              Language = 26
+	LanguageTaiwanese            Language = 27
+)
+
+// HoldMode is the action requested by a Hold request.
+type HoldMode string
+
+// Hold modes as defined by the protocol.
+const (
+	HoldAdd    HoldMode = "+"
+	HoldDelete HoldMode = "-"
+	HoldChange HoldMode = "*"
+)
+
+// YesNoUnknown is the value of the flags FieldMagneticMedia and
+// FieldDesentisize, which the ACS may report as unknown.
+type YesNoUnknown string
+
+// Values of YesNoUnknown.
+const (
+	Yes     YesNoUnknown = "Y"
+	No      YesNoUnknown = "N"
+	Unknown YesNoUnknown = "U"
+)
+
+// intWidths are the widths of numeric variable-length fields which are
+// zero-padded. Fixed-length fields have the width given by fixedFieldLengths.
+var intWidths = map[fieldType]int{
+	FieldHoldItemsLimit:    4,
+	FieldOverdueItemsLimit: 4,
+	FieldChargedItemsLimit: 4,
+	FieldMediaType:         3,
+	FieldFeeType:           2,
+}
+
+// fieldEncoder adds typed values as fields to a Message.
+type fieldEncoder struct {
+	m Message
+}
+
+func (e fieldEncoder) string(f fieldType, v string) {
+	e.m.AddField(Field{Type: f, Value: v})
+}
+
+func (e fieldEncoder) optString(f fieldType, v string) {
+	if v != "" {
+		e.string(f, v)
+	}
+}
+
+func (e fieldEncoder) strings(f fieldType, vs []string) {
+	for _, v := range vs {
+		e.string(f, v)
+	}
+}
+
+func (e fieldEncoder) bool(f fieldType, v bool) {
+	if v {
+		e.string(f, "Y")
+	} else {
+		e.string(f, "N")
+	}
+}
+
+func (e fieldEncoder) optBool(f fieldType, v *bool) {
+	if v != nil {
+		e.bool(f, *v)
+	}
+}
+
+// bit encodes the 0/1 flags FieldOK and FieldItemPropertiesOK.
+func (e fieldEncoder) bit(f fieldType, v bool) {
+	if v {
+		e.string(f, "1")
+	} else {
+		e.string(f, "0")
+	}
+}
+
+func (e fieldEncoder) int(f fieldType, v int) {
+	w, ok := fixedFieldLengths[f]
+	if !ok {
+		w = intWidths[f]
+	}
+	e.string(f, fmt.Sprintf("%0*d", w, v))
+}
+
+func (e fieldEncoder) optInt(f fieldType, v int) {
+	if v != 0 {
+		e.int(f, v)
+	}
+}
+
+// time converts a timestamp field. The zero time, for a date which is not
+// set, is blank, as the decoder expects.
+func (e fieldEncoder) time(f fieldType, v time.Time) {
+	if v.IsZero() {
+		e.string(f, strings.Repeat(" ", len(DateLayout)))
+		return
+	}
+	e.string(f, v.Format(DateLayout))
+}
+
+func (e fieldEncoder) optTime(f fieldType, v time.Time) {
+	if !v.IsZero() {
+		e.time(f, v)
+	}
+}
+
+// fieldDecoder converts the fields of a Message to typed values,
+// keeping the first conversion error.
+type fieldDecoder struct {
+	m   Message
+	err error
+}
+
+func (d *fieldDecoder) fail(f fieldType, v string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("sip2: %v: invalid %v: %q", d.m.typ, f, v)
+	}
+}
+
+func (d *fieldDecoder) bool(f fieldType) bool {
+	switch v := d.m.Field(f); v {
+	case "Y":
+		return true
+	case "N", "":
+		return false
+	default:
+		d.fail(f, v)
+		return false
+	}
+}
+
+func (d *fieldDecoder) optBool(f fieldType) *bool {
+	if _, ok := d.m.FieldOK(f); !ok {
+		return nil
+	}
+	b := d.bool(f)
+	return &b
+}
+
+func (d *fieldDecoder) bit(f fieldType) bool {
+	switch v := d.m.Field(f); v {
+	case "1":
+		return true
+	case "0", "":
+		return false
+	default:
+		d.fail(f, v)
+		return false
+	}
+}
+
+// int converts a numeric field. Blank values, which the protocol allows
+// for unknown counts, are returned as 0.
+func (d *fieldDecoder) int(f fieldType) int {
+	v := strings.TrimSpace(d.m.Field(f))
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		d.fail(f, d.m.Field(f))
+	}
+	return n
+}
+
+// time converts a timestamp field of the form YYYYMMDDZZZZHHMMSS, where the
+// time zone ZZZZ is either blank for local time, or ends with Z for UTC.
+func (d *fieldDecoder) time(f fieldType) time.Time {
+	v := d.m.Field(f)
+	if strings.TrimSpace(v) == "" {
+		return time.Time{}
+	}
+	if len(v) != len(DateLayout) {
+		d.fail(f, v)
+		return time.Time{}
+	}
+	loc := time.Local
+	if strings.TrimSpace(v[8:12]) == "Z" {
+		loc = time.UTC
+	}
+	t, err := time.ParseInLocation("20060102150405", v[:8]+v[12:], loc)
+	if err != nil {
+		d.fail(f, v)
+	}
+	return t
+}
+
+func (d *fieldDecoder) checkType(t msgType) bool {
+	if d.m.typ != t {
+		d.err = fmt.Errorf("sip2: cannot convert %v to %v", d.m.typ, t)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package sip2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	yes := true
+	date := time.Date(2016, 8, 22, 15, 34, 50, 0, time.Local)
+
+	tests := []struct {
+		msg   string
+		typed TypedMessage
+	}{
+		{
+			"11YN20160822    15345020160822    153450AO|AAN0012121212|ABix:1156620,1|ACsecret|BOY|\r",
+			&CheckoutRequest{
+				RenewalPolicy:    true,
+				TransactionDate:  date,
+				NbDueDate:        date,
+				PatronIdentifier: "N0012121212",
+				ItemIdentifier:   "ix:1156620,1",
+				TerminalPassword: "secret",
+				FeeAcknowledged:  &yes,
+			},
+		},
+		{
+			"101YNY20160822    153450AO|ABix:1544245,1|AQfuru|AJSy!|AAxyz|CK001|AFWelcome|\r",
+			&CheckinResponse{
+				OK:                true,
+				Resensitize:       true,
+				MagneticMedia:     No,
+				Alert:             true,
+				TransactionDate:   date,
+				ItemIdentifier:    "ix:1544245,1",
+				PermanentLocation: "furu",
+				TitleIdentifier:   "Sy!",
+				PatronIdentifier:  "xyz",
+				MediaType:         MediaBook,
+				ScreenMessage:     []string{"Welcome"},
+			},
+		},
+		{
+			"1808000120160822    153450CF2|AH20160901|CJ20160905    120000|ABix:1|AJSy!|\r",
+			&ItemInformationResponse{
+				CirculationStatus: CirculationWaitingOnHold,
+				SecurityMarker:    SecurityMarkerOther,
+				FeeType:           FeeOther,
+				TransactionDate:   date,
+				HoldQueueLength:   2,
+				DueDate:           "20160901",
+				RecallDate:        time.Date(2016, 9, 5, 12, 0, 0, 0, time.Local),
+				ItemIdentifier:    "ix:1",
+				TitleIdentifier:   "Sy!",
+			},
+		},
+		{
+			"64              00020160822    153450000000010000000000000000AOinst|AAxyz|AEJane|BZ0010|AUix:1|AUix:2|\r",
+			&PatronInformationResponse{
+				PatronStatus:      "              ",
+				Language:          LanguageUnknown,
+				TransactionDate:   date,
+				OverdueItemsCount: 1,
+				InstitutionID:     "inst",
+				PatronIdentifier:  "xyz",
+				PersonalName:      "Jane",
+				HoldItemsLimit:    10,
+				ChargedItems:      []string{"ix:1", "ix:2"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		m, err := Decode([]byte(test.msg))
+		if err != nil {
+			t.Fatalf("Decode(%q) => %v", test.msg, err)
+		}
+		got, err := m.Typed()
+		if err != nil {
+			t.Errorf("Typed() of %q => %v", test.msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.typed) {
+			t.Errorf("Typed() of %q =>\n%+v; want\n%+v", test.msg, got, test.typed)
+		}
+		if s := test.typed.Message().String(); s != test.msg {
+			t.Errorf("%T.Message() =>\n%q; want\n%q", test.typed, s, test.msg)
+		}
+	}
+}
+
+func TestTypedBlankCounts(t *testing.T) {
+	m, err := Decode([]byte("64              00020160822    153450    0001000000000000    AOinst|AAxyz|AEJane|\r"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r PatronInformationResponse
+	if err := r.FromMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if r.HoldItemsCount != 0 || r.OverdueItemsCount != 1 || r.UnavailableHoldsCount != 0 {
+		t.Errorf("counts => %d, %d, %d; want 0, 1, 0", r.HoldItemsCount, r.OverdueItemsCount, r.UnavailableHoldsCount)
+	}
+	// Blank counts are encoded as zeros.
+	if got, want := r.Message().String(), "64              00020160822    153450000000010000000000000000AOinst|AAxyz|AEJane|\r"; got != want {
+		t.Errorf("Message() =>\n%q; want\n%q", got, want)
+	}
+}
+
+func TestTypedUnsetDate(t *testing.T) {
+	req := &CheckoutRequest{
+		TransactionDate:  time.Date(2016, 8, 22, 15, 34, 50, 0, time.Local),
+		PatronIdentifier: "p1",
+		ItemIdentifier:   "i1",
+	}
+	m := req.Message()
+	if got, want := m.Field(FieldNbDueDate), "                  "; got != want {
+		t.Errorf("unset NbDueDate encoded as %q; want %q", got, want)
+	}
+	if errs := m.Validate(); len(errs) > 0 {
+		t.Errorf("message with unset date is not valid: %v", errs)
+	}
+	var got CheckoutRequest
+	if err := got.FromMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, req) {
+		t.Errorf("round-trip of %+v =>\n%+v", req, &got)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		msg  Message
+		into TypedMessage
+	}{
+		{NewMessage(MsgReqCheckin), &CheckoutRequest{}},
+		{NewMessage(MsgRespLogin).AddField(Field{Type: FieldOK, Value: "Y"}), &LoginResponse{}},
+		{NewMessage(MsgRespRenewAll).AddField(Field{Type: FieldRenewedCount, Value: "12a4"}), &RenewAllResponse{}},
+		{NewMessage(MsgReqCheckout).AddField(Field{Type: FieldTransactionDate, Value: "2016"}), &CheckoutRequest{}},
+	}
+
+	for _, test := range tests {
+		if err := test.into.FromMessage(test.msg); err == nil {
+			t.Errorf("%T.FromMessage(%v) => nil error; want error", test.into, test.msg)
+		}
+	}
+}